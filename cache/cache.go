@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"context"
+	"encoding/json/v2"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+const DefaultLoadTimeout = 30 * time.Second
+
+// ErrNotFound is returned by a loader when the value does not exist.
+// With Options.NegativeTTL > 0, the absence is cached and GetOrLoad returns ErrNotFound without loading
+var ErrNotFound = errors.New("cache: not found")
+
+// ErrInvalidTTL is returned by Set and GetOrLoad when ttl is not positive
+var ErrInvalidTTL = errors.New("cache: ttl must be positive")
+
+type Options struct {
+	// StaleTTL is the stale-while-revalidate window after the TTL.
+	// Within it, GetOrLoad returns the stale value and reloads it in the background. 0 = disabled
+	StaleTTL time.Duration
+	// NegativeTTL is the TTL of caching ErrNotFound from a loader. 0 = disabled
+	NegativeTTL time.Duration
+	// LoadTimeout bounds a loader. 0 = DefaultLoadTimeout
+	// Loaders run detached from the callers' contexts since they are shared by concurrent callers
+	LoadTimeout time.Duration
+}
+
+// Cache stores values of T as JSON in a kvdb.Client under Namespace
+type Cache[T any] struct {
+	Namespace string // key prefix. e.g. Core.AppName + "_cache:users"
+	Opts      Options
+
+	client  kvdb.Client
+	flights flightGroup[T]
+}
+
+// entry is the stored envelope of a value
+type entry[T any] struct {
+	Value      T     `json:"v,omitzero"`
+	Negative   bool  `json:"neg,omitzero"`
+	FreshUntil int64 `json:"fresh_until"` // unix milliseconds. stale after this
+}
+
+func New[T any](client kvdb.Client, namespace string, opts Options) *Cache[T] {
+	return &Cache[T]{
+		Namespace: namespace,
+		Opts:      opts,
+		client:    client,
+	}
+}
+
+// fullKey prefixes the values apart from the tag sets, so that no key collides with a tag. e.g. key "tag:x" vs tag "x"
+func (c *Cache[T]) fullKey(key string) string {
+	return c.Namespace + ":v:" + key
+}
+
+func (c *Cache[T]) tagKey(tag string) string {
+	return c.Namespace + ":t:" + tag
+}
+
+// Get returns the cached value, including a stale one within StaleTTL.
+// A cached absence (negative) is reported as not found
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, bool, error) { // val, found, err
+	var zero T
+	e, found, err := c.getEntry(ctx, c.fullKey(key))
+	if err != nil || !found || e.Negative {
+		return zero, false, err
+	}
+	return e.Value, true, nil
+}
+
+// Set caches value for ttl (+ StaleTTL) and associates it with tags for InvalidateTags. ttl must be positive
+func (c *Cache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration, tags ...string) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	return c.setEntry(ctx, c.fullKey(key), entry[T]{Value: value}, ttl, tags)
+}
+
+// Delete removes the cached values
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	fullKeys := make([]string, len(keys))
+	for i, key := range keys {
+		fullKeys[i] = c.fullKey(key)
+	}
+	_, err := c.client.Delete(ctx, fullKeys...)
+	return err
+}
+
+// InvalidateTags removes all cached values set with any of the tags
+func (c *Cache[T]) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tagKey := c.tagKey(tag)
+		keys, err := c.client.SMembers(ctx, tagKey)
+		if err != nil {
+			return err
+		}
+		// expired keys in the tag set are deleted harmlessly
+		if _, err = c.client.Delete(ctx, append(keys, tagKey)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetOrLoad returns the cached value, or loads it with loader and caches it for ttl.
+//   - concurrent loads of the same key in this process are deduplicated
+//   - a stale value within StaleTTL is returned as is while it is reloaded in the background
+//   - ErrNotFound from loader is cached for NegativeTTL
+//
+// Errors of the kvdb are logged and the value is loaded as if not cached. ttl must be positive
+func (c *Cache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), tags ...string) (T, error) {
+	var zero T
+	if ttl <= 0 {
+		return zero, ErrInvalidTTL
+	}
+	fullKey := c.fullKey(key)
+	e, found, err := c.getEntry(ctx, fullKey)
+	if err != nil {
+		log.Printf("[WARN] cache get %s failed: %v", fullKey, err)
+	}
+	if found {
+		if time.Now().UnixMilli() >= e.FreshUntil { // stale
+			c.flights.start(fullKey, c.loadFunc(fullKey, ttl, loader, tags))
+		}
+		if e.Negative {
+			return zero, ErrNotFound
+		}
+		return e.Value, nil
+	}
+	return c.flights.start(fullKey, c.loadFunc(fullKey, ttl, loader, tags)).wait(ctx)
+}
+
+// loadFunc returns a flight loading and caching a value
+func (c *Cache[T]) loadFunc(fullKey string, ttl time.Duration, loader func(ctx context.Context) (T, error), tags []string) func() (T, error) {
+	return func() (T, error) {
+		timeout := c.Opts.LoadTimeout
+		if timeout <= 0 {
+			timeout = DefaultLoadTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		val, err := loader(ctx)
+		if errors.Is(err, ErrNotFound) {
+			if c.Opts.NegativeTTL > 0 {
+				if setErr := c.setEntry(ctx, fullKey, entry[T]{Negative: true}, c.Opts.NegativeTTL, tags); setErr != nil {
+					log.Printf("[WARN] cache set %s failed: %v", fullKey, setErr)
+				}
+			}
+			return val, ErrNotFound
+		}
+		if err != nil {
+			return val, err
+		}
+		if setErr := c.setEntry(ctx, fullKey, entry[T]{Value: val}, ttl, tags); setErr != nil {
+			log.Printf("[WARN] cache set %s failed: %v", fullKey, setErr)
+		}
+		return val, nil
+	}
+}
+
+func (c *Cache[T]) getEntry(ctx context.Context, fullKey string) (entry[T], bool, error) {
+	var e entry[T]
+	data, found, err := c.client.Get(ctx, fullKey)
+	if err != nil || !found {
+		return e, false, err
+	}
+	if err = json.Unmarshal([]byte(data), &e); err != nil {
+		// e.g. T has changed. treated as a miss to be overwritten
+		log.Printf("[WARN] cache entry %s invalid: %v", fullKey, err)
+		return e, false, nil
+	}
+	return e, true, nil
+}
+
+// setEntry stores e for ttl + StaleTTL.
+// The tag sets live as long as the longest entry in them
+func (c *Cache[T]) setEntry(ctx context.Context, fullKey string, e entry[T], ttl time.Duration, tags []string) error {
+	e.FreshUntil = time.Now().Add(ttl).UnixMilli()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	expiration := ttl + c.Opts.StaleTTL
+	if len(tags) == 0 {
+		return c.client.Set(ctx, fullKey, data, expiration)
+	}
+
+	ttlBatch := c.client.Pipeline()
+	tagTTLs := make([]*kvdb.Result[time.Duration], len(tags))
+	for i, tag := range tags {
+		tagTTLs[i] = ttlBatch.TTL(c.tagKey(tag))
+	}
+	if err = ttlBatch.Exec(ctx); err != nil {
+		return err
+	}
+	b := c.client.TxPipeline()
+	b.Set(fullKey, data, expiration)
+	for i, tag := range tags {
+		b.SAdd(c.tagKey(tag), fullKey)
+		if tagTTL, found, _ := tagTTLs[i].Result(); !found || (tagTTL != kvdb.NoExpiration && tagTTL < expiration) {
+			b.Expire(c.tagKey(tag), expiration)
+		}
+	}
+	return b.Exec(ctx)
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// flightGroup runs at most one load per key at a time. Callers join the load in flight
+type flightGroup[T any] struct {
+	mu      sync.Mutex
+	flights map[string]*flight[T]
+}
+
+type flight[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// start returns the flight of key in progress, or starts fn in a new one
+func (g *flightGroup[T]) start(key string, fn func() (T, error)) *flight[T] {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if f, ok := g.flights[key]; ok {
+		return f
+	}
+	if g.flights == nil {
+		g.flights = make(map[string]*flight[T])
+	}
+	f := &flight[T]{done: make(chan struct{})}
+	g.flights[key] = f
+	go func() {
+		defer func() {
+			if r := recover(); r != nil { // a loader panic must not crash the process
+				f.err = fmt.Errorf("cache: loader panic: %v", r)
+			}
+			g.mu.Lock()
+			delete(g.flights, key)
+			g.mu.Unlock()
+			close(f.done)
+		}()
+		f.val, f.err = fn()
+	}()
+	return f
+}
+
+// wait waits for the result, or returns ctx.Err() leaving the flight running
+func (f *flight[T]) wait(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
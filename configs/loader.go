@@ -0,0 +1,250 @@
+package configs
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultEnvPrefix = "GW"  // e.g. GW_ENV, GW_SQLDB_MAIN_PW
+	EnvNameSuffix    = "ENV" // <prefix>_ENV selects the per-environment file
+)
+
+// Loader loads JSON config files in layers
+//  1. base file                e.g. config/.core.json
+//  2. per-environment file     e.g. config/.core.production.json (optional)
+//  3. environment variables    e.g. GW_CORE_LISTEN=:8080
+//  4. secret references        e.g. "pw": "${file:/run/secrets/db_pw}" or "pw": "${env:DB_PW}"
+//
+// Environment variables override keys already present in the merged file layers.
+// The variable name is <EnvPrefix>_<SECTION>_<PATH> with every key upper-cased and
+// non-alphanumeric characters replaced by '_'. e.g. {"main":{"pw":"..."}} in section "SQLDB" -> GW_SQLDB_MAIN_PW
+type Loader struct {
+	Dir       string // config directory. e.g. <AppRoot>/config
+	Env       string // environment name. e.g. production. empty = base files only
+	EnvPrefix string // environment variable prefix. default: DefaultEnvPrefix
+
+	// LookupEnv is used for overrides and `${env:…}` references. default: os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+
+	mu       sync.Mutex
+	resolved map[string]*resolvedConf // section -> last resolved config
+	order    []string                 // section display order
+	staged   map[string]*resolvedConf // Loads between Stage and Commit. nil = not staging
+}
+
+type resolvedConf struct {
+	file    string
+	tree    any
+	secrets map[string]struct{} // paths resolved from secret references
+}
+
+// NewLoader creates a Loader reading files in dir.
+// The environment name is taken from <DefaultEnvPrefix>_ENV. e.g. GW_ENV=production
+func NewLoader(dir string) *Loader {
+	l := &Loader{
+		Dir:       dir,
+		EnvPrefix: DefaultEnvPrefix,
+		LookupEnv: os.LookupEnv,
+	}
+	l.Env, _ = l.lookupEnv(l.EnvPrefix + "_" + EnvNameSuffix)
+	return l
+}
+
+// Load resolves all layers of a config file and decodes the result into v.
+// fileName is the base file name in Dir. e.g. ".core.json"
+// section is the environment variable section. e.g. "CORE" -> GW_CORE_*
+func (l *Loader) Load(fileName string, section string, v any) error {
+	tree, secrets, err := l.Resolve(fileName, section)
+	if err != nil {
+		return err
+	}
+	treeBytes, err := json.Marshal(tree)
+	if err != nil {
+		return fmt.Errorf("configs: %s: %w", fileName, err)
+	}
+	if err = json.Unmarshal(treeBytes, v); err != nil {
+		return fmt.Errorf("configs: %s: %w", fileName, err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rc := &resolvedConf{file: fileName, tree: tree, secrets: secrets}
+	if l.staged != nil {
+		l.staged[envKey(section)] = rc
+		return nil
+	}
+	l.record(envKey(section), rc)
+	return nil
+}
+
+// Stage keeps the configs loaded from now on out of Dump until Commit, so that a rolled-back reload does not show them.
+// Discard drops them
+func (l *Loader) Stage() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.staged = make(map[string]*resolvedConf)
+}
+
+// Commit records the configs loaded since Stage
+func (l *Loader) Commit() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, rc := range l.staged {
+		l.record(key, rc)
+	}
+	l.staged = nil
+}
+
+// Discard drops the configs loaded since Stage. The last committed ones are kept
+func (l *Loader) Discard() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.staged = nil
+}
+
+func (l *Loader) record(key string, rc *resolvedConf) {
+	if l.resolved == nil {
+		l.resolved = make(map[string]*resolvedConf)
+	}
+	if _, exists := l.resolved[key]; !exists {
+		l.order = append(l.order, key)
+	}
+	l.resolved[key] = rc
+}
+
+// Resolve returns the merged generic JSON tree of a config file without decoding it into a type,
+// and the set of paths whose values came from secret references.
+func (l *Loader) Resolve(fileName string, section string) (any, map[string]struct{}, error) {
+	// 1. Base
+	tree, err := l.readTree(filepath.Join(l.Dir, fileName))
+	if err != nil {
+		return nil, nil, err
+	}
+	// 2. Per-environment
+	if l.Env != "" {
+		envTree, err := l.readTree(filepath.Join(l.Dir, EnvFileName(fileName, l.Env)))
+		switch {
+		case err == nil:
+			tree = mergeTrees(tree, envTree)
+		case errors.Is(err, os.ErrNotExist):
+			// optional
+		default:
+			return nil, nil, err
+		}
+	}
+	// 3. Environment variables
+	prefix := envKey(l.envPrefix())
+	if section != "" {
+		prefix += "_" + envKey(section)
+	}
+	if tree, err = l.applyEnvOverrides(tree, prefix); err != nil {
+		return nil, nil, fmt.Errorf("configs: %s: %w", fileName, err)
+	}
+	// 4. Secret references
+	secrets := make(map[string]struct{})
+	if tree, err = l.resolveSecrets(tree, "", secrets); err != nil {
+		return nil, nil, fmt.Errorf("configs: %s: %w", fileName, err)
+	}
+	return tree, secrets, nil
+}
+
+// Dump writes all configs loaded so far as indented JSON, keyed by section, with secrets redacted
+func (l *Loader) Dump(w io.Writer) error {
+	l.mu.Lock()
+	out := make(map[string]any, len(l.resolved))
+	for _, section := range l.order {
+		rc := l.resolved[section]
+		out[section] = map[string]any{
+			"file":   rc.file,
+			"values": redact(rc.tree, "", rc.secrets),
+		}
+	}
+	l.mu.Unlock()
+	return json.MarshalWrite(w, out, json.Deterministic(true), jsontext.WithIndent("  "))
+}
+
+// EnvFileName inserts the environment name before the extension. e.g. (".core.json", "production") -> ".core.production.json"
+func EnvFileName(fileName string, env string) string {
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "." + env + ext
+}
+
+func (l *Loader) envPrefix() string {
+	if l.EnvPrefix == "" {
+		return DefaultEnvPrefix
+	}
+	return l.EnvPrefix
+}
+
+func (l *Loader) lookupEnv(key string) (string, bool) {
+	if l.LookupEnv == nil {
+		return os.LookupEnv(key)
+	}
+	return l.LookupEnv(key)
+}
+
+// readTree reads a JSON file into a generic tree
+// Numbers are kept as raw jsontext.Value so that large integers survive the round trip
+func (l *Loader) readTree(path string) (any, error) {
+	fileBytes, err := os.ReadFile(path) // ([]byte, error)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	if err = json.Unmarshal(fileBytes, &tree, json.WithUnmarshalers(rawNumbers)); err != nil {
+		return nil, fmt.Errorf("configs: %s: %w", path, err)
+	}
+	log.Printf("[INFO][CONFIG] loaded %s", path)
+	return tree, nil
+}
+
+var rawNumbers = json.UnmarshalFromFunc(func(dec *jsontext.Decoder, v *any) error {
+	if dec.PeekKind() != '0' {
+		return errors.ErrUnsupported // default handling
+	}
+	val, err := dec.ReadValue()
+	if err != nil {
+		return err
+	}
+	*v = val.Clone()
+	return nil
+})
+
+// mergeTrees deep-merges src into dst. Objects are merged key by key; anything else in src replaces dst
+func mergeTrees(dst any, src any) any {
+	dstMap, ok1 := dst.(map[string]any)
+	srcMap, ok2 := src.(map[string]any)
+	if !ok1 || !ok2 {
+		return src
+	}
+	for k, v := range srcMap {
+		if old, exists := dstMap[k]; exists {
+			dstMap[k] = mergeTrees(old, v)
+		} else {
+			dstMap[k] = v
+		}
+	}
+	return dstMap
+}
+
+// envKey upper-cases s and replaces non-alphanumeric characters with '_'
+func envKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
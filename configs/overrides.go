@@ -0,0 +1,64 @@
+package configs
+
+import (
+	"encoding/json/jsontext"
+	"fmt"
+	"strconv"
+)
+
+// applyEnvOverrides replaces values in the tree with environment variables named <prefix>_<PATH>
+// Values are converted to the type of the value they replace.
+// Objects and arrays can be replaced as a whole with a JSON value. e.g. GW_MAINBACKEND_VERIFY_EXTERNAL_CODE='{"google":"/v1/google"}'
+func (l *Loader) applyEnvOverrides(node any, envName string) (any, error) {
+	if raw, ok := l.lookupEnv(envName); ok {
+		return convertEnvValue(node, raw, envName)
+	}
+	switch typed := node.(type) {
+	case map[string]any:
+		for k, v := range typed {
+			newV, err := l.applyEnvOverrides(v, envName+"_"+envKey(k))
+			if err != nil {
+				return nil, err
+			}
+			typed[k] = newV
+		}
+	case []any:
+		for i, v := range typed {
+			newV, err := l.applyEnvOverrides(v, envName+"_"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			typed[i] = newV
+		}
+	}
+	return node, nil
+}
+
+func convertEnvValue(old any, raw string, envName string) (any, error) {
+	switch old.(type) {
+	case string:
+		return raw, nil
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid bool %q", envName, raw)
+		}
+		return b, nil
+	case jsontext.Value: // number
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, fmt.Errorf("%s: invalid number %q", envName, raw)
+		}
+		return jsontext.Value(raw), nil
+	case nil: // null. the type is unknown, so a value which is not JSON is taken as a string. e.g. GW_CORE_HOST=example.com
+		if val := jsontext.Value(raw); val.IsValid() {
+			return val, nil
+		}
+		return raw, nil
+	default: // object, array
+		val := jsontext.Value(raw)
+		if !val.IsValid() {
+			return nil, fmt.Errorf("%s: invalid JSON value", envName)
+		}
+		return val, nil
+	}
+}
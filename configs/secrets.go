@@ -0,0 +1,128 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A secret reference is a whole string value wrapped in the marker, so that plain values such as URLs are never misread
+const (
+	SecretRefFile = "${file:" // "${file:/run/secrets/db_pw}". relative paths are resolved against Loader.Dir
+	SecretRefEnv  = "${env:"  // "${env:DB_PW}"
+	SecretRefEnd  = "}"
+)
+
+// resolveSecrets replaces string values that are secret references with the referenced secrets
+// and records their paths into secrets
+func (l *Loader) resolveSecrets(node any, path string, secrets map[string]struct{}) (any, error) {
+	switch typed := node.(type) {
+	case string:
+		secret, isRef, err := l.resolveSecretRef(typed)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if !isRef {
+			return typed, nil
+		}
+		secrets[path] = struct{}{}
+		return secret, nil
+	case map[string]any:
+		for k, v := range typed {
+			newV, err := l.resolveSecrets(v, joinPath(path, k), secrets)
+			if err != nil {
+				return nil, err
+			}
+			typed[k] = newV
+		}
+	case []any:
+		for i, v := range typed {
+			newV, err := l.resolveSecrets(v, joinPath(path, strconv.Itoa(i)), secrets)
+			if err != nil {
+				return nil, err
+			}
+			typed[i] = newV
+		}
+	}
+	return node, nil
+}
+
+// redact returns a copy of the tree with secret values replaced
+func redact(node any, path string, secrets map[string]struct{}) any {
+	if _, ok := secrets[path]; ok {
+		return RedactedValue
+	}
+	switch typed := node.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		for k, v := range typed {
+			if _, isScalar := v.(string); isScalar && IsSensitiveKey(k) {
+				out[k] = RedactedValue
+				continue
+			}
+			out[k] = redact(v, joinPath(path, k), secrets)
+		}
+		return out
+	case []any:
+		out := make([]any, len(typed))
+		for i, v := range typed {
+			out[i] = redact(v, joinPath(path, strconv.Itoa(i)), secrets)
+		}
+		return out
+	}
+	return node
+}
+
+const RedactedValue = "<redacted>"
+
+var sensitiveKeyParts = []string{"password", "passwd", "secret", "enckey", "dsn"}
+
+// IsSensitiveKey reports whether a config key is redacted on Dump even if it was not a secret reference
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if key == "pw" || strings.HasSuffix(key, "_pw") {
+		return true
+	}
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// resolveSecretRef returns (secret, isRef, err)
+func (l *Loader) resolveSecretRef(val string) (string, bool, error) {
+	if !strings.HasSuffix(val, SecretRefEnd) {
+		return "", false, nil
+	}
+	switch {
+	case strings.HasPrefix(val, SecretRefFile):
+		path := strings.TrimSuffix(strings.TrimPrefix(val, SecretRefFile), SecretRefEnd)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(l.Dir, path)
+		}
+		secretBytes, err := os.ReadFile(path)
+		if err != nil {
+			return "", true, fmt.Errorf("cannot read secret file: %w", err)
+		}
+		return strings.TrimRight(string(secretBytes), "\r\n"), true, nil
+	case strings.HasPrefix(val, SecretRefEnv):
+		name := strings.TrimSuffix(strings.TrimPrefix(val, SecretRefEnv), SecretRefEnd)
+		secret, ok := l.lookupEnv(name)
+		if !ok {
+			return "", true, fmt.Errorf("secret env %q not set", name)
+		}
+		return secret, true, nil
+	}
+	return "", false, nil
+}
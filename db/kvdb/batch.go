@@ -0,0 +1,131 @@
+package kvdb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotExecuted is the error of a Result whose Batch has not been executed yet
+	ErrNotExecuted = errors.New("kvdb: batch not executed")
+	// ErrTxFailed is returned by Exec of a Batch from Tx when a watched key has been modified. No command is executed
+	ErrTxFailed = errors.New("kvdb: transaction failed. watched key modified")
+)
+
+// Batch queues commands of the Client interface and sends them in one round trip on Exec.
+//   - Client.Pipeline: not atomic. commands of other clients may interleave
+//   - Client.TxPipeline: atomic (MULTI/EXEC). no other command runs in between
+//
+// As in Redis, a failing command (e.g. ErrWrongType) does not roll back or stop the others,
+// even in a transaction. Each queued command returns a Result, available after Exec.
+// A Batch is not safe for concurrent use and must not be reused after Exec.
+type Batch interface {
+	//---- Key Ops ----
+
+	Exists(key string) *Result[bool]
+	Delete(keys ...string) *Result[int64]
+	Expire(key string, expiration time.Duration) *Result[bool]
+	TTL(key string) *Result[time.Duration] // Found: key found
+	Persist(key string) *Result[bool]
+
+	//---- Single-value Ops ----
+
+	Set(key string, value any, expiration time.Duration) *Result[struct{}]
+	Get(key string) *Result[string] // Found: key found
+	SetNX(key string, value any, expiration time.Duration) *Result[bool]
+	SetXX(key string, value any, expiration time.Duration) *Result[bool]
+	GetDel(key string) *Result[string] // Found: key found
+
+	//---- Counter Ops ----
+
+	Incr(key string) *Result[int64]
+	IncrBy(key string, n int64) *Result[int64]
+	Decr(key string) *Result[int64]
+
+	//---- List Ops ----
+
+	Push(key string, value string) *Result[struct{}]
+	Pop(key string) *Result[string]                 // Found: an element popped
+	PopPush(src string, dst string) *Result[string] // Found: an element moved
+	Len(key string) *Result[int64]
+	Range(key string, start int64, stop int64) *Result[[]string]
+	Remove(key string, cnt int64, value any) *Result[int64]
+	Trim(key string, start int64, stop int64) *Result[struct{}]
+
+	//---- Hash Ops ----
+
+	SetField(key string, field string, value any) *Result[struct{}]
+	GetField(key string, field string) *Result[string] // Found: field found
+	SetFields(key string, fields map[string]any) *Result[struct{}]
+	GetFields(key string, fields ...string) *Result[map[string]string]
+	RemoveFields(key string, fields ...string) *Result[int64]
+	GetAllFields(key string) *Result[map[string]string]
+
+	//---- Set Ops ----
+
+	SAdd(key string, members ...any) *Result[int64]
+	SRem(key string, members ...any) *Result[int64]
+	SMembers(key string) *Result[[]string]
+	SIsMember(key string, member any) *Result[bool]
+
+	//---- Sorted Set Ops ----
+
+	ZAdd(key string, members ...ZMember) *Result[int64]
+	ZRangeByScore(key string, min float64, max float64, limit int64) *Result[[]ZMember]
+	ZRem(key string, members ...string) *Result[int64]
+	ZCard(key string) *Result[int64]
+
+	//---- Rate Limit Ops ----
+
+	Throttle(key string, burst int64, emission time.Duration, cost int64, maxDelay time.Duration) *Result[ThrottleResult]
+
+	// Queued returns the number of queued commands
+	Queued() int
+	// Exec executes the queued commands and sets their Results.
+	// Returns the first command error (not-found is not an error), or ErrTxFailed
+	Exec(ctx context.Context) error
+}
+
+// Tx is an optimistic transaction started by Client.Watch
+type Tx interface {
+	// TxPipeline returns an atomic Batch executed only if none of the watched keys
+	// has been modified since Watch. Otherwise, its Exec returns ErrTxFailed
+	TxPipeline() Batch
+}
+
+// Result is the result of a command queued in a Batch
+type Result[T any] struct {
+	val   T
+	found bool
+	err   error
+}
+
+// NewResult returns a pending Result. For Batch implementations
+func NewResult[T any]() *Result[T] {
+	return &Result[T]{err: ErrNotExecuted}
+}
+
+// Resolve sets the outcome of the command. For Batch implementations
+func (r *Result[T]) Resolve(val T, found bool, err error) {
+	r.val, r.found, r.err = val, found, err
+}
+
+func (r *Result[T]) Val() T {
+	return r.val
+}
+
+// Found reports whether the key (or field, element) was found for the commands with a found flag.
+// For the other commands, whether the command succeeded
+func (r *Result[T]) Found() bool {
+	return r.found
+}
+
+func (r *Result[T]) Err() error {
+	return r.err
+}
+
+// Result returns val, found, err in the order of the Client methods
+func (r *Result[T]) Result() (T, bool, error) {
+	return r.val, r.found, r.err
+}
@@ -3,12 +3,14 @@ package kvdb
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 )
 
 type Client interface {
 	Init() error
 	Close() error
+	Ping(ctx context.Context) error
 	GetHandle() any // generic handle. ToDo: kvdb.Handle
 	GetConf() *Conf
 
@@ -19,27 +21,46 @@ type Client interface {
 	// Expire sets/updates expiration for a key
 	Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) // found & updated, err
 
+	// TTL returns the remaining time to live of a key. ttl = NoExpiration if the key has no expiration
+	TTL(ctx context.Context, key string) (time.Duration, bool, error) // ttl, found, err
+	// Persist removes the expiration of a key. Returns false if the key is not found or has no expiration
+	Persist(ctx context.Context, key string) (bool, error)
+
 	// Type returns the string representation of the value type stored at the given key.
 	Type(ctx context.Context, key string) (string, error)
 
-	// ScanKeys iterates over keys in the database in batches.
+	// ScanKeys iterates over keys matching a glob-style pattern in batches. empty match = all keys
 	// Returns keys []string, nextCurosr any, err error
 	// It attempts to return up to scanBatchSize keys starting from the given cursor.
 	// The exact number of keys returned may vary depending on the backend's scanning behavior.
 	// The cursor type and meaning are backend-specific and opaque to callers.
 	// When nextCursor is nil, the scan is complete.
 	// Backends that do not support key iteration (e.g. Memcached) should return ErrNotSupported.
-	ScanKeys(ctx context.Context, cursor any, scanBatchSize int) ([]string, any, error)
+	ScanKeys(ctx context.Context, cursor any, match string, scanBatchSize int) ([]string, any, error)
 
 	//---- Single-value Ops ----
 
 	Set(ctx context.Context, key string, value any, expiration time.Duration) error
 	Get(ctx context.Context, key string) (string, bool, error) // val, found, err
+	// SetNX sets only if the key does not exist. Returns true if set
+	SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error)
+	// SetXX sets only if the key already exists. Returns true if set
+	SetXX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error)
+	GetDel(ctx context.Context, key string) (string, bool, error) // val, found, err
+
+	//---- Counter Ops ----
+	// A missing key counts from 0. ErrNotInteger if the value is not an integer
+
+	Incr(ctx context.Context, key string) (int64, error)
+	IncrBy(ctx context.Context, key string, n int64) (int64, error)
+	Decr(ctx context.Context, key string) (int64, error)
 
 	//---- List Ops ----
 
 	Push(ctx context.Context, key string, value string) error
 	Pop(ctx context.Context, key string) (string, bool, error) // val, found, err
+	// PopPush atomically pops from the head of src and pushes to the tail of dst (LMOVE LEFT RIGHT)
+	PopPush(ctx context.Context, src string, dst string) (string, bool, error) // val, found, err
 	Len(ctx context.Context, key string) (int64, error)
 	Range(ctx context.Context, key string, start int64, stop int64) ([]string, error) // 0-basis, stop inclusive
 	Remove(ctx context.Context, key string, cnt int64, value any) (int64, error)      // cnt = removed dups. 0 = all
@@ -55,6 +76,84 @@ type Client interface {
 	// RemoveFields removes the specified fields in a hash key. Returns the number of fields actually removed.
 	RemoveFields(ctx context.Context, key string, fields ...string) (int64, error)
 	GetAllFields(ctx context.Context, key string) (map[string]string, error)
+
+	//---- Set Ops ----
+
+	SAdd(ctx context.Context, key string, members ...any) (int64, error) // number of members actually added
+	SRem(ctx context.Context, key string, members ...any) (int64, error) // number of members actually removed
+	SMembers(ctx context.Context, key string) ([]string, error)
+	SIsMember(ctx context.Context, key string, member any) (bool, error)
+
+	//---- Sorted Set Ops ----
+
+	ZAdd(ctx context.Context, key string, members ...ZMember) (int64, error) // number of members newly added. scores of existing ones are updated
+	// ZRangeByScore returns members with min <= score <= max in ascending order. Use math.Inf for open ends. limit 0 = all
+	ZRangeByScore(ctx context.Context, key string, min float64, max float64, limit int64) ([]ZMember, error)
+	ZRem(ctx context.Context, key string, members ...string) (int64, error) // number of members actually removed
+	ZCard(ctx context.Context, key string) (int64, error)
+
+	//---- Rate Limit Ops ----
+
+	// Throttle evaluates a token bucket on key atomically in one round trip, as CL.THROTTLE of redis-cell.
+	// A token is added every emission up to burst, and cost tokens are consumed if available. 0 cost = peek.
+	// If not available yet but within maxDelay (0 = now only), they are reserved in advance:
+	// Allowed with RetryAfter = the wait before using them. Negative cost gives back tokens. e.g. a cancelled reservation
+	// The key holds the theoretical arrival time (GCRA) with a TTL, on the clock of the server
+	Throttle(ctx context.Context, key string, burst int64, emission time.Duration, cost int64, maxDelay time.Duration) (ThrottleResult, error)
+
+	//---- Batch Ops ----
+
+	// Pipeline returns a Batch sent in one round trip, not atomically
+	Pipeline() Batch
+	// TxPipeline returns a Batch executed atomically (MULTI/EXEC)
+	TxPipeline() Batch
+	// Watch watches keys and runs fn, which reads the keys with the Client and
+	// executes the writes with a Batch from tx.TxPipeline().
+	// It returns the error of fn as is; ErrTxFailed if a watched key has been modified meanwhile.
+	// Callers usually retry on ErrTxFailed
+	Watch(ctx context.Context, fn func(tx Tx) error, keys ...string) error
+
+	//---- Pub/Sub Ops ----
+
+	// Publish sends payload to the subscribers of channel. Returns the number of subscribers which received it
+	Publish(ctx context.Context, channel string, payload any) (int64, error)
+	// Subscribe subscribes to channels. Names with glob special characters (see IsPattern) are subscribed as patterns.
+	// The subscription is restored automatically when the connection is re-established.
+	// The returned channel is closed when ctx is done or the Client is closed
+	Subscribe(ctx context.Context, channels ...string) (<-chan Message, error)
+}
+
+// ZMember is a member of a sorted set
+type ZMember struct {
+	Score  float64
+	Member string
+}
+
+// ThrottleResult is the result of Throttle
+type ThrottleResult struct {
+	Allowed    bool          // consumed now or reserved
+	Remaining  int64         // tokens left
+	RetryAfter time.Duration // until cost tokens are available. 0 if Allowed now
+	ResetAfter time.Duration // until the bucket is full again
+}
+
+// CheckThrottleArgs validates the arguments of Throttle. cost over burst would never be allowed
+func CheckThrottleArgs(burst int64, emission time.Duration, cost int64, maxDelay time.Duration) error {
+	if burst <= 0 || emission <= 0 || cost < -burst || cost > burst || maxDelay < 0 {
+		return fmt.Errorf("%w: throttle burst=%d emission=%v cost=%d max_delay=%v", ErrInvalidArgument, burst, emission, cost, maxDelay)
+	}
+	return nil
 }
 
-var ErrNotSupported = errors.New("kvdb: operation not supported")
+// NoExpiration is the TTL of a key without expiration
+const NoExpiration time.Duration = -1
+
+var (
+	ErrNotSupported = errors.New("kvdb: operation not supported")
+	// ErrWrongType is returned when an operation is applied to a key holding another type of value
+	ErrWrongType = errors.New("kvdb: operation against a key holding the wrong kind of value")
+	// ErrNotInteger is returned by counter ops when the value is not an integer
+	ErrNotInteger = errors.New("kvdb: value is not an integer or out of range")
+	// ErrInvalidArgument is returned when an operation is called with invalid arguments
+	ErrInvalidArgument = errors.New("kvdb: invalid argument")
+)
@@ -7,4 +7,8 @@ type Conf struct {
 	//Driver string `json:"driver"`
 	PW string `json:"pw"`
 	DB int    `json:"db"` // optional db number e.g. redis
+
+	// For in-process implementations e.g. memory
+	SnapshotPath     string `json:"snapshot_path"`     // optional. load on Init, save on Close
+	SnapshotInterval int    `json:"snapshot_interval"` // seconds between periodic snapshots. 0 = on Close only
 }
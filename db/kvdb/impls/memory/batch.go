@@ -0,0 +1,355 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+// batch runs the queued commands with the Client methods.
+// An atomic batch holds c.mu during the whole Exec and runs them against an unlocked view of the items
+type batch struct {
+	c       *Client
+	atomic  bool
+	watched map[string]*item // copies of the watched items at Watch. nil item = key not found
+	cmds    []batchCmd
+}
+
+type batchCmd struct {
+	run  func(ctx context.Context, c *Client) error
+	fail func(err error)
+}
+
+// Ensure batch implements kvdb.Batch interface
+var _ kvdb.Batch = (*batch)(nil)
+
+func (c *Client) Pipeline() kvdb.Batch {
+	return &batch{c: c}
+}
+
+func (c *Client) TxPipeline() kvdb.Batch {
+	return &batch{c: c, atomic: true}
+}
+
+// Watch copies the watched items and compares them on Exec of the tx batch.
+// Unlike Redis, a key modified and restored to the same value and TTL in between is not a conflict
+func (c *Client) Watch(ctx context.Context, fn func(tx kvdb.Tx) error, keys ...string) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return errClosed
+	}
+	watched := make(map[string]*item, len(keys))
+	for _, key := range keys {
+		it, _ := c.lookup(key)
+		watched[key] = it.clone()
+	}
+	c.mu.Unlock()
+	return fn(&tx{c: c, watched: watched})
+}
+
+type tx struct {
+	c       *Client
+	watched map[string]*item
+}
+
+func (t *tx) TxPipeline() kvdb.Batch {
+	return &batch{c: t.c, atomic: true, watched: t.watched}
+}
+
+func (b *batch) Queued() int {
+	return len(b.cmds)
+}
+
+func (b *batch) Exec(ctx context.Context) error {
+	cmds := b.cmds
+	b.cmds = nil
+	if !b.atomic {
+		return runCmds(ctx, b.c, cmds)
+	}
+	b.c.mu.Lock()
+	defer b.c.mu.Unlock()
+	if b.c.closed {
+		failCmds(cmds, errClosed)
+		return errClosed
+	}
+	for key, w := range b.watched {
+		cur, _ := b.c.lookup(key)
+		if !w.equal(cur) {
+			failCmds(cmds, kvdb.ErrTxFailed)
+			return kvdb.ErrTxFailed
+		}
+	}
+	// the view shares the items. its own mutex is never contended while b.c.mu is held
+	view := &Client{Conf: b.c.Conf, items: b.c.items}
+	return runCmds(ctx, view, cmds)
+}
+
+func runCmds(ctx context.Context, c *Client, cmds []batchCmd) error {
+	var firstErr error
+	for _, cmd := range cmds {
+		if err := cmd.run(ctx, c); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func failCmds(cmds []batchCmd, err error) {
+	for _, cmd := range cmds {
+		cmd.fail(err)
+	}
+}
+
+// queue adds a command resolving a new Result
+func queue[T any](b *batch, fn func(ctx context.Context, c *Client) (T, bool, error)) *kvdb.Result[T] {
+	r := kvdb.NewResult[T]()
+	b.cmds = append(b.cmds, batchCmd{
+		run: func(ctx context.Context, c *Client) error {
+			val, found, err := fn(ctx, c)
+			r.Resolve(val, found, err)
+			return err
+		},
+		fail: func(err error) {
+			var zero T
+			r.Resolve(zero, false, err)
+		},
+	})
+	return r
+}
+
+// succeeded adapts (val, err) results to (val, found, err). found = succeeded
+func succeeded[T any](val T, err error) (T, bool, error) {
+	return val, err == nil, err
+}
+
+func status(err error) (struct{}, bool, error) {
+	return struct{}{}, err == nil, err
+}
+
+//---- Key Ops ----
+
+func (b *batch) Exists(key string) *kvdb.Result[bool] {
+	return queue(b, func(ctx context.Context, c *Client) (bool, bool, error) {
+		return succeeded(c.Exists(ctx, key))
+	})
+}
+
+func (b *batch) Delete(keys ...string) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.Delete(ctx, keys...))
+	})
+}
+
+func (b *batch) Expire(key string, expiration time.Duration) *kvdb.Result[bool] {
+	return queue(b, func(ctx context.Context, c *Client) (bool, bool, error) {
+		return succeeded(c.Expire(ctx, key, expiration))
+	})
+}
+
+func (b *batch) TTL(key string) *kvdb.Result[time.Duration] {
+	return queue(b, func(ctx context.Context, c *Client) (time.Duration, bool, error) {
+		return c.TTL(ctx, key)
+	})
+}
+
+func (b *batch) Persist(key string) *kvdb.Result[bool] {
+	return queue(b, func(ctx context.Context, c *Client) (bool, bool, error) {
+		return succeeded(c.Persist(ctx, key))
+	})
+}
+
+//---- Single-value Ops ----
+
+func (b *batch) Set(key string, value any, expiration time.Duration) *kvdb.Result[struct{}] {
+	return queue(b, func(ctx context.Context, c *Client) (struct{}, bool, error) {
+		return status(c.Set(ctx, key, value, expiration))
+	})
+}
+
+func (b *batch) Get(key string) *kvdb.Result[string] {
+	return queue(b, func(ctx context.Context, c *Client) (string, bool, error) {
+		return c.Get(ctx, key)
+	})
+}
+
+func (b *batch) SetNX(key string, value any, expiration time.Duration) *kvdb.Result[bool] {
+	return queue(b, func(ctx context.Context, c *Client) (bool, bool, error) {
+		return succeeded(c.SetNX(ctx, key, value, expiration))
+	})
+}
+
+func (b *batch) SetXX(key string, value any, expiration time.Duration) *kvdb.Result[bool] {
+	return queue(b, func(ctx context.Context, c *Client) (bool, bool, error) {
+		return succeeded(c.SetXX(ctx, key, value, expiration))
+	})
+}
+
+func (b *batch) GetDel(key string) *kvdb.Result[string] {
+	return queue(b, func(ctx context.Context, c *Client) (string, bool, error) {
+		return c.GetDel(ctx, key)
+	})
+}
+
+//---- Counter Ops ----
+
+func (b *batch) Incr(key string) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.Incr(ctx, key))
+	})
+}
+
+func (b *batch) IncrBy(key string, n int64) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.IncrBy(ctx, key, n))
+	})
+}
+
+func (b *batch) Decr(key string) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.Decr(ctx, key))
+	})
+}
+
+//---- List Ops ----
+
+func (b *batch) Push(key string, value string) *kvdb.Result[struct{}] {
+	return queue(b, func(ctx context.Context, c *Client) (struct{}, bool, error) {
+		return status(c.Push(ctx, key, value))
+	})
+}
+
+func (b *batch) Pop(key string) *kvdb.Result[string] {
+	return queue(b, func(ctx context.Context, c *Client) (string, bool, error) {
+		return c.Pop(ctx, key)
+	})
+}
+
+func (b *batch) PopPush(src string, dst string) *kvdb.Result[string] {
+	return queue(b, func(ctx context.Context, c *Client) (string, bool, error) {
+		return c.PopPush(ctx, src, dst)
+	})
+}
+
+func (b *batch) Len(key string) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.Len(ctx, key))
+	})
+}
+
+func (b *batch) Range(key string, start int64, stop int64) *kvdb.Result[[]string] {
+	return queue(b, func(ctx context.Context, c *Client) ([]string, bool, error) {
+		return succeeded(c.Range(ctx, key, start, stop))
+	})
+}
+
+func (b *batch) Remove(key string, cnt int64, value any) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.Remove(ctx, key, cnt, value))
+	})
+}
+
+func (b *batch) Trim(key string, start int64, stop int64) *kvdb.Result[struct{}] {
+	return queue(b, func(ctx context.Context, c *Client) (struct{}, bool, error) {
+		return status(c.Trim(ctx, key, start, stop))
+	})
+}
+
+//---- Hash Ops ----
+
+func (b *batch) SetField(key string, field string, value any) *kvdb.Result[struct{}] {
+	return queue(b, func(ctx context.Context, c *Client) (struct{}, bool, error) {
+		return status(c.SetField(ctx, key, field, value))
+	})
+}
+
+func (b *batch) GetField(key string, field string) *kvdb.Result[string] {
+	return queue(b, func(ctx context.Context, c *Client) (string, bool, error) {
+		return c.GetField(ctx, key, field)
+	})
+}
+
+func (b *batch) SetFields(key string, fields map[string]any) *kvdb.Result[struct{}] {
+	return queue(b, func(ctx context.Context, c *Client) (struct{}, bool, error) {
+		return status(c.SetFields(ctx, key, fields))
+	})
+}
+
+func (b *batch) GetFields(key string, fields ...string) *kvdb.Result[map[string]string] {
+	return queue(b, func(ctx context.Context, c *Client) (map[string]string, bool, error) {
+		return succeeded(c.GetFields(ctx, key, fields...))
+	})
+}
+
+func (b *batch) RemoveFields(key string, fields ...string) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.RemoveFields(ctx, key, fields...))
+	})
+}
+
+func (b *batch) GetAllFields(key string) *kvdb.Result[map[string]string] {
+	return queue(b, func(ctx context.Context, c *Client) (map[string]string, bool, error) {
+		return succeeded(c.GetAllFields(ctx, key))
+	})
+}
+
+//---- Set Ops ----
+
+func (b *batch) SAdd(key string, members ...any) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.SAdd(ctx, key, members...))
+	})
+}
+
+func (b *batch) SRem(key string, members ...any) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.SRem(ctx, key, members...))
+	})
+}
+
+func (b *batch) SMembers(key string) *kvdb.Result[[]string] {
+	return queue(b, func(ctx context.Context, c *Client) ([]string, bool, error) {
+		return succeeded(c.SMembers(ctx, key))
+	})
+}
+
+func (b *batch) SIsMember(key string, member any) *kvdb.Result[bool] {
+	return queue(b, func(ctx context.Context, c *Client) (bool, bool, error) {
+		return succeeded(c.SIsMember(ctx, key, member))
+	})
+}
+
+//---- Sorted Set Ops ----
+
+func (b *batch) ZAdd(key string, members ...kvdb.ZMember) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.ZAdd(ctx, key, members...))
+	})
+}
+
+func (b *batch) ZRangeByScore(key string, min float64, max float64, limit int64) *kvdb.Result[[]kvdb.ZMember] {
+	return queue(b, func(ctx context.Context, c *Client) ([]kvdb.ZMember, bool, error) {
+		return succeeded(c.ZRangeByScore(ctx, key, min, max, limit))
+	})
+}
+
+func (b *batch) ZRem(key string, members ...string) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.ZRem(ctx, key, members...))
+	})
+}
+
+func (b *batch) ZCard(key string) *kvdb.Result[int64] {
+	return queue(b, func(ctx context.Context, c *Client) (int64, bool, error) {
+		return succeeded(c.ZCard(ctx, key))
+	})
+}
+
+//---- Rate Limit Ops ----
+
+func (b *batch) Throttle(key string, burst int64, emission time.Duration, cost int64, maxDelay time.Duration) *kvdb.Result[kvdb.ThrottleResult] {
+	return queue(b, func(ctx context.Context, c *Client) (kvdb.ThrottleResult, bool, error) {
+		return succeeded(c.Throttle(ctx, key, burst, emission, cost, maxDelay))
+	})
+}
@@ -0,0 +1,307 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+// Client is an in-process kvdb.Client following the Redis semantics
+// e.g. empty lists/hashes are removed, missing keys are read as empty values
+// For tests and single-node deployments
+type Client struct {
+	Conf *kvdb.Conf
+
+	mu     sync.Mutex
+	items  map[string]*item
+	closed bool
+	stop   chan struct{}
+	wg     sync.WaitGroup
+
+	subsMu sync.Mutex
+	subs   map[*subscription]struct{}
+}
+
+// Ensure memory.Client implements kvdb.Client interface
+var _ kvdb.Client = (*Client)(nil)
+
+var errClosed = errors.New("memory kvdb: client closed")
+
+func NewClient(conf *kvdb.Conf) (kvdb.Client, error) {
+	return &Client{Conf: conf}, nil
+}
+
+func (c *Client) Init() error {
+	if c.Conf == nil {
+		c.Conf = &kvdb.Conf{Type: DBType}
+	}
+	c.items = make(map[string]*item)
+	c.stop = make(chan struct{})
+	if c.Conf.SnapshotPath != "" {
+		if err := c.loadSnapshot(); err != nil {
+			return err
+		}
+	}
+	c.wg.Add(1)
+	go c.runJanitor()
+	log.Println("[INFO] memory kvdb initialized")
+	return nil
+}
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed || c.stop == nil {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.stop)
+	c.mu.Unlock()
+	c.wg.Wait()
+	if c.Conf.SnapshotPath != "" {
+		return c.SaveSnapshot()
+	}
+	return nil
+}
+
+func (c *Client) Ping(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errClosed
+	}
+	return nil
+}
+
+func (c *Client) GetHandle() any { // no lower-level handle. the client itself
+	return c
+}
+
+func (c *Client) GetConf() *kvdb.Conf {
+	return c.Conf
+}
+
+// runJanitor removes expired keys in the background and takes periodic snapshots
+func (c *Client) runJanitor() {
+	defer c.wg.Done()
+	expiryTicker := time.NewTicker(JanitorInterval)
+	defer expiryTicker.Stop()
+	var snapshotC <-chan time.Time
+	if c.Conf.SnapshotPath != "" && c.Conf.SnapshotInterval > 0 {
+		snapshotTicker := time.NewTicker(time.Duration(c.Conf.SnapshotInterval) * time.Second)
+		defer snapshotTicker.Stop()
+		snapshotC = snapshotTicker.C
+	}
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-expiryTicker.C:
+			c.mu.Lock()
+			for key, it := range c.items {
+				if it.expired(now) {
+					delete(c.items, key)
+				}
+			}
+			c.mu.Unlock()
+		case <-snapshotC:
+			if err := c.SaveSnapshot(); err != nil {
+				log.Printf("[ERROR] memory kvdb snapshot failed: %v", err)
+			}
+		}
+	}
+}
+
+// lookup returns a live item. expired items are removed lazily
+// Caller must hold c.mu
+func (c *Client) lookup(key string) (*item, bool) {
+	it, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	if it.expired(time.Now()) {
+		delete(c.items, key)
+		return nil, false
+	}
+	return it, true
+}
+
+// lookupType returns a live item of the kind. (nil, nil) if not found
+// Caller must hold c.mu
+func (c *Client) lookupType(key string, kind string) (*item, error) {
+	if c.closed {
+		return nil, errClosed
+	}
+	it, ok := c.lookup(key)
+	if !ok {
+		return nil, nil
+	}
+	if it.kind != kind {
+		return nil, kvdb.ErrWrongType
+	}
+	return it, nil
+}
+
+// lookupOrCreate returns a live item of the kind, creating it if not found
+// Caller must hold c.mu
+func (c *Client) lookupOrCreate(key string, kind string) (*item, error) {
+	it, err := c.lookupType(key, kind)
+	if err != nil || it != nil {
+		return it, err
+	}
+	it = newItem(kind)
+	c.items[key] = it
+	return it, nil
+}
+
+// removeIfEmpty deletes a container key left empty as Redis does
+// Caller must hold c.mu
+func (c *Client) removeIfEmpty(key string, it *item) {
+	if it.empty() {
+		delete(c.items, key)
+	}
+}
+
+//---- Key Ops ----
+
+func (c *Client) Exists(_ context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false, errClosed
+	}
+	_, ok := c.lookup(key)
+	return ok, nil
+}
+
+func (c *Client) Delete(_ context.Context, keys ...string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, errClosed
+	}
+	var n int64
+	for _, key := range keys {
+		if _, ok := c.lookup(key); ok {
+			delete(c.items, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Expire sets/updates expiration for a key. A non-positive expiration deletes the key as Redis does
+func (c *Client) Expire(_ context.Context, key string, expiration time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false, errClosed
+	}
+	it, ok := c.lookup(key)
+	if !ok {
+		return false, nil
+	}
+	if expiration <= 0 {
+		delete(c.items, key)
+		return true, nil
+	}
+	it.expireAt = time.Now().Add(expiration)
+	return true, nil
+}
+
+func (c *Client) TTL(_ context.Context, key string) (time.Duration, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, false, errClosed
+	}
+	it, ok := c.lookup(key)
+	if !ok {
+		return 0, false, nil
+	}
+	if it.expireAt.IsZero() {
+		return kvdb.NoExpiration, true, nil
+	}
+	return time.Until(it.expireAt), true, nil
+}
+
+func (c *Client) Persist(_ context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false, errClosed
+	}
+	it, ok := c.lookup(key)
+	if !ok || it.expireAt.IsZero() {
+		return false, nil
+	}
+	it.expireAt = time.Time{}
+	return true, nil
+}
+
+func (c *Client) Type(_ context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return "", errClosed
+	}
+	it, ok := c.lookup(key)
+	if !ok {
+		return TypeNone, nil
+	}
+	return it.kind, nil
+}
+
+// ScanKeys returns keys in lexical order. The cursor is the last returned key (string).
+// nil, "" and a zero integer (the start cursor of redis) start the scan.
+// Keys present during the whole iteration are returned exactly once.
+func (c *Client) ScanKeys(_ context.Context, cursor any, match string, scanBatchSize int) ([]string, any, error) {
+	var (
+		after     string
+		badCursor bool
+	)
+	switch cur := cursor.(type) {
+	case nil:
+	case string:
+		after = cur
+	case int:
+		badCursor = cur != 0
+	case int64:
+		badCursor = cur != 0
+	case uint64:
+		badCursor = cur != 0
+	default:
+		badCursor = true
+	}
+	if badCursor {
+		return nil, nil, fmt.Errorf("%w: memory scan cursor %#v is not a key", kvdb.ErrInvalidArgument, cursor)
+	}
+	if scanBatchSize <= 0 {
+		scanBatchSize = 10
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, nil, errClosed
+	}
+	now := time.Now()
+	keys := make([]string, 0, len(c.items))
+	for key, it := range c.items {
+		if key > after && !it.expired(now) && (match == "" || kvdb.MatchPattern(match, key)) {
+			keys = append(keys, key)
+		}
+	}
+	c.mu.Unlock()
+	slices.Sort(keys)
+	if len(keys) <= scanBatchSize {
+		return keys, nil, nil
+	}
+	keys = keys[:scanBatchSize]
+	return keys, keys[len(keys)-1], nil
+}
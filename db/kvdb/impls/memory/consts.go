@@ -0,0 +1,21 @@
+package memory
+
+import "time"
+
+const DBType = "memory"
+
+// Value types reported by Type. Same as Redis TYPE
+const (
+	TypeNone   = "none"
+	TypeString = "string"
+	TypeList   = "list"
+	TypeHash   = "hash"
+	TypeSet    = "set"
+	TypeZSet   = "zset"
+)
+
+// JanitorInterval is the cycle of removing expired keys in the background
+const JanitorInterval = time.Second
+
+// SubscriptionBufferSize is the number of messages buffered for each subscription
+const SubscriptionBufferSize = 100
@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"encoding"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type item struct {
+	kind     string // TypeString, TypeList, TypeHash, TypeSet, TypeZSet
+	str      string
+	list     []string
+	hash     map[string]string
+	set      map[string]struct{}
+	zset     map[string]float64 // member -> score
+	expireAt time.Time          // zero = no expiration
+}
+
+func newItem(kind string) *item {
+	it := &item{kind: kind}
+	switch kind {
+	case TypeHash:
+		it.hash = make(map[string]string)
+	case TypeSet:
+		it.set = make(map[string]struct{})
+	case TypeZSet:
+		it.zset = make(map[string]float64)
+	}
+	return it
+}
+
+func (it *item) expired(now time.Time) bool {
+	return !it.expireAt.IsZero() && !now.Before(it.expireAt)
+}
+
+func (it *item) empty() bool {
+	switch it.kind {
+	case TypeList:
+		return len(it.list) == 0
+	case TypeHash:
+		return len(it.hash) == 0
+	case TypeSet:
+		return len(it.set) == 0
+	case TypeZSet:
+		return len(it.zset) == 0
+	default:
+		return false
+	}
+}
+
+// clone returns a deep copy. nil-safe
+func (it *item) clone() *item {
+	if it == nil {
+		return nil
+	}
+	cp := *it
+	cp.list = slices.Clone(it.list)
+	cp.hash = maps.Clone(it.hash)
+	cp.set = maps.Clone(it.set)
+	cp.zset = maps.Clone(it.zset)
+	return &cp
+}
+
+// equal compares type, value and expiration. nil-safe
+func (it *item) equal(other *item) bool {
+	if it == nil || other == nil {
+		return it == other
+	}
+	return it.kind == other.kind &&
+		it.expireAt.Equal(other.expireAt) &&
+		it.str == other.str &&
+		slices.Equal(it.list, other.list) &&
+		maps.Equal(it.hash, other.hash) &&
+		maps.Equal(it.set, other.set) &&
+		maps.Equal(it.zset, other.zset)
+}
+
+// formatValue converts a value into the string stored, the same way the Redis client serializes arguments
+func formatValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 64), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return strconv.FormatInt(v.Nanoseconds(), 10), nil
+	case encoding.BinaryMarshaler:
+		b, err := v.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return "", fmt.Errorf("memory kvdb: can't marshal %T (implement encoding.BinaryMarshaler)", value)
+	}
+}
+
+func formatValues(values []any) ([]string, error) {
+	strs := make([]string, len(values))
+	for i, value := range values {
+		str, err := formatValue(value)
+		if err != nil {
+			return nil, err
+		}
+		strs[i] = str
+	}
+	return strs, nil
+}
+
+// listIndexes converts Redis-style start/stop (negative = from the tail, stop inclusive)
+// into a Go slice range [from, to). ok is false if the range is empty
+func listIndexes(length int, start int64, stop int64) (int, int, bool) {
+	n := int64(length)
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	start = max(start, 0)
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop || start >= n {
+		return 0, 0, false
+	}
+	return int(start), int(stop) + 1, true
+}
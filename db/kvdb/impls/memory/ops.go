@@ -0,0 +1,359 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+//---- Single-value Ops ----
+
+func (c *Client) Get(_ context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeString)
+	if err != nil || it == nil {
+		return "", false, err
+	}
+	return it.str, true, nil
+}
+
+// Set overwrites any value type. expiration 0 = no expiration
+func (c *Client) Set(_ context.Context, key string, value any, expiration time.Duration) error {
+	str, err := formatValue(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errClosed
+	}
+	it := &item{kind: TypeString, str: str}
+	if expiration > 0 {
+		it.expireAt = time.Now().Add(expiration)
+	}
+	c.items[key] = it
+	return nil
+}
+
+func (c *Client) SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	return c.setIf(key, value, expiration, false)
+}
+
+func (c *Client) SetXX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	return c.setIf(key, value, expiration, true)
+}
+
+// setIf sets a string value only if the key existence equals mustExist
+func (c *Client) setIf(key string, value any, expiration time.Duration, mustExist bool) (bool, error) {
+	str, err := formatValue(value)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false, errClosed
+	}
+	if _, exists := c.lookup(key); exists != mustExist {
+		return false, nil
+	}
+	it := &item{kind: TypeString, str: str}
+	if expiration > 0 {
+		it.expireAt = time.Now().Add(expiration)
+	}
+	c.items[key] = it
+	return true, nil
+}
+
+func (c *Client) GetDel(_ context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeString)
+	if err != nil || it == nil {
+		return "", false, err
+	}
+	delete(c.items, key)
+	return it.str, true, nil
+}
+
+//---- Counter Ops ----
+
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	return c.IncrBy(ctx, key, 1)
+}
+
+func (c *Client) Decr(ctx context.Context, key string) (int64, error) {
+	return c.IncrBy(ctx, key, -1)
+}
+
+// IncrBy keeps the expiration of an existing key as Redis does
+func (c *Client) IncrBy(_ context.Context, key string, n int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupOrCreate(key, TypeString)
+	if err != nil {
+		return 0, err
+	}
+	var cur int64
+	if it.str != "" {
+		if cur, err = strconv.ParseInt(it.str, 10, 64); err != nil {
+			return 0, kvdb.ErrNotInteger
+		}
+	}
+	if (n > 0 && cur > math.MaxInt64-n) || (n < 0 && cur < math.MinInt64-n) {
+		return 0, kvdb.ErrNotInteger // overflow
+	}
+	cur += n
+	it.str = strconv.FormatInt(cur, 10)
+	return cur, nil
+}
+
+//---- List Ops ----
+
+func (c *Client) Push(_ context.Context, key string, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupOrCreate(key, TypeList)
+	if err != nil {
+		return err
+	}
+	// to the tail (right) of the list
+	it.list = append(it.list, value)
+	return nil
+}
+
+func (c *Client) Pop(_ context.Context, key string) (string, bool, error) { // val, found, err
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeList)
+	if err != nil || it == nil {
+		return "", false, err
+	}
+	// from the head (left) of the list (FIFO)
+	val := it.list[0]
+	it.list = it.list[1:]
+	c.removeIfEmpty(key, it)
+	return val, true, nil
+}
+
+func (c *Client) PopPush(_ context.Context, src string, dst string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	from, err := c.lookupType(src, TypeList)
+	if err != nil || from == nil {
+		return "", false, err
+	}
+	// check dst type before popping as Redis does
+	to, err := c.lookupOrCreate(dst, TypeList)
+	if err != nil {
+		return "", false, err
+	}
+	val := from.list[0]
+	from.list = from.list[1:]
+	to.list = append(to.list, val)
+	c.removeIfEmpty(src, from)
+	return val, true, nil
+}
+
+func (c *Client) Len(_ context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeList)
+	if err != nil || it == nil {
+		return 0, err
+	}
+	return int64(len(it.list)), nil
+}
+
+func (c *Client) Range(_ context.Context, key string, start int64, stop int64) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeList)
+	if err != nil {
+		return nil, err
+	}
+	if it == nil {
+		return []string{}, nil
+	}
+	from, to, ok := listIndexes(len(it.list), start, stop)
+	if !ok {
+		return []string{}, nil
+	}
+	return append([]string(nil), it.list[from:to]...), nil
+}
+
+// Remove removes occurrences of value. cnt > 0: from the head, cnt < 0: from the tail, cnt = 0: all
+func (c *Client) Remove(_ context.Context, key string, cnt int64, value any) (int64, error) {
+	str, err := formatValue(value)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeList)
+	if err != nil || it == nil {
+		return 0, err
+	}
+	limit := cnt
+	if limit < 0 {
+		limit = -limit
+	}
+	var removed int64
+	kept := make([]string, 0, len(it.list))
+	if cnt >= 0 {
+		for _, v := range it.list {
+			if v == str && (limit == 0 || removed < limit) {
+				removed++
+				continue
+			}
+			kept = append(kept, v)
+		}
+	} else {
+		for i := len(it.list) - 1; i >= 0; i-- {
+			v := it.list[i]
+			if v == str && removed < limit {
+				removed++
+				continue
+			}
+			kept = append(kept, v)
+		}
+		// reverse back
+		for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+			kept[i], kept[j] = kept[j], kept[i]
+		}
+	}
+	it.list = kept
+	c.removeIfEmpty(key, it)
+	return removed, nil
+}
+
+func (c *Client) Trim(_ context.Context, key string, start int64, stop int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeList)
+	if err != nil || it == nil {
+		return err
+	}
+	from, to, ok := listIndexes(len(it.list), start, stop)
+	if !ok {
+		it.list = nil
+	} else {
+		it.list = append([]string(nil), it.list[from:to]...)
+	}
+	c.removeIfEmpty(key, it)
+	return nil
+}
+
+//---- Hash Ops ----
+
+func (c *Client) SetField(_ context.Context, key string, field string, value any) error {
+	str, err := formatValue(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupOrCreate(key, TypeHash)
+	if err != nil {
+		return err
+	}
+	it.hash[field] = str
+	return nil
+}
+
+func (c *Client) GetField(_ context.Context, key string, field string) (string, bool, error) { // val, found, err
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeHash)
+	if err != nil || it == nil {
+		return "", false, err // key or field missing
+	}
+	val, ok := it.hash[field]
+	return val, ok, nil
+}
+
+func (c *Client) SetFields(_ context.Context, key string, fields map[string]any) error {
+	strs := make(map[string]string, len(fields))
+	for field, value := range fields {
+		str, err := formatValue(value)
+		if err != nil {
+			return err
+		}
+		strs[field] = str
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupOrCreate(key, TypeHash)
+	if err != nil {
+		return err
+	}
+	for field, str := range strs {
+		it.hash[field] = str
+	}
+	c.removeIfEmpty(key, it)
+	return nil
+}
+
+// GetFields returns a map {field:value} from a hash data, which contains only found fields
+// so, if len(rtnMap) < len(fields), some fields are missing
+// [NOTE] returns an empty map even if key is not found. not error
+func (c *Client) GetFields(_ context.Context, key string, fields ...string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeHash)
+	if err != nil {
+		return nil, err
+	}
+	rtnMap := make(map[string]string, len(fields))
+	if it == nil {
+		return rtnMap, nil
+	}
+	for _, field := range fields {
+		if val, ok := it.hash[field]; ok {
+			rtnMap[field] = val
+		}
+	}
+	return rtnMap, nil
+}
+
+func (c *Client) RemoveFields(_ context.Context, key string, fields ...string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeHash)
+	if err != nil || it == nil {
+		return 0, err
+	}
+	var removed int64
+	for _, field := range fields {
+		if _, ok := it.hash[field]; ok {
+			delete(it.hash, field)
+			removed++
+		}
+	}
+	c.removeIfEmpty(key, it)
+	return removed, nil
+}
+
+// GetAllFields returns a map {field:value} from a hash data with all fields in it
+// [NOTE] returns an empty map even if key is not found. not error
+func (c *Client) GetAllFields(_ context.Context, key string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeHash)
+	if err != nil {
+		return nil, err
+	}
+	rtnMap := make(map[string]string)
+	if it == nil {
+		return rtnMap, nil
+	}
+	for field, val := range it.hash {
+		rtnMap[field] = val
+	}
+	return rtnMap, nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"log"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+type subscription struct {
+	channels map[string]struct{}
+	patterns []string
+	msgCh    chan kvdb.Message
+}
+
+// Publish delivers to the subscribers in this process only.
+// A subscriber whose buffer (SubscriptionBufferSize) is full misses the message, as Redis disconnects slow subscribers
+func (c *Client) Publish(_ context.Context, channel string, payload any) (int64, error) {
+	str, err := formatValue(payload)
+	if err != nil {
+		return 0, err
+	}
+	if err = c.Ping(context.Background()); err != nil {
+		return 0, err
+	}
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	var received int64
+	for sub := range c.subs {
+		// as Redis, a message matching both a channel and patterns is delivered for each
+		if _, ok := sub.channels[channel]; ok {
+			received += sub.deliver(kvdb.Message{Channel: channel, Payload: str})
+		}
+		for _, pattern := range sub.patterns {
+			if kvdb.MatchPattern(pattern, channel) {
+				received += sub.deliver(kvdb.Message{Channel: channel, Pattern: pattern, Payload: str})
+			}
+		}
+	}
+	return received, nil
+}
+
+// deliver returns 1 if delivered, 0 if dropped
+func (s *subscription) deliver(msg kvdb.Message) int64 {
+	select {
+	case s.msgCh <- msg:
+		return 1
+	default:
+		log.Printf("[WARN] memory kvdb subscriber buffer full. message on %s dropped", msg.Channel)
+		return 0
+	}
+}
+
+func (c *Client) Subscribe(ctx context.Context, channels ...string) (<-chan kvdb.Message, error) {
+	sub := &subscription{
+		channels: make(map[string]struct{}),
+		msgCh:    make(chan kvdb.Message, SubscriptionBufferSize),
+	}
+	for _, ch := range channels {
+		if kvdb.IsPattern(ch) {
+			sub.patterns = append(sub.patterns, ch)
+		} else {
+			sub.channels[ch] = struct{}{}
+		}
+	}
+	c.mu.Lock()
+	if c.closed || c.stop == nil {
+		c.mu.Unlock()
+		return nil, errClosed
+	}
+	c.wg.Add(1)
+	c.mu.Unlock()
+
+	c.subsMu.Lock()
+	if c.subs == nil {
+		c.subs = make(map[*subscription]struct{})
+	}
+	c.subs[sub] = struct{}{}
+	c.subsMu.Unlock()
+
+	go func() {
+		defer c.wg.Done()
+		select {
+		case <-ctx.Done():
+		case <-c.stop:
+		}
+		c.subsMu.Lock()
+		delete(c.subs, sub)
+		close(sub.msgCh) // under subsMu. Publish never sends to it after this
+		c.subsMu.Unlock()
+	}()
+	return sub.msgCh, nil
+}
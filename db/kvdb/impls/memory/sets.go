@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+//---- Set Ops ----
+
+func (c *Client) SAdd(_ context.Context, key string, members ...any) (int64, error) {
+	strs, err := formatValues(members)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupOrCreate(key, TypeSet)
+	if err != nil {
+		return 0, err
+	}
+	var added int64
+	for _, member := range strs {
+		if _, exists := it.set[member]; !exists {
+			it.set[member] = struct{}{}
+			added++
+		}
+	}
+	c.removeIfEmpty(key, it)
+	return added, nil
+}
+
+func (c *Client) SRem(_ context.Context, key string, members ...any) (int64, error) {
+	strs, err := formatValues(members)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeSet)
+	if err != nil || it == nil {
+		return 0, err
+	}
+	var removed int64
+	for _, member := range strs {
+		if _, exists := it.set[member]; exists {
+			delete(it.set, member)
+			removed++
+		}
+	}
+	c.removeIfEmpty(key, it)
+	return removed, nil
+}
+
+// SMembers returns members in no particular order. empty slice if key is not found
+func (c *Client) SMembers(_ context.Context, key string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeSet)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]string, 0)
+	if it == nil {
+		return members, nil
+	}
+	for member := range it.set {
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (c *Client) SIsMember(_ context.Context, key string, member any) (bool, error) {
+	str, err := formatValue(member)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeSet)
+	if err != nil || it == nil {
+		return false, err
+	}
+	_, ok := it.set[str]
+	return ok, nil
+}
+
+//---- Sorted Set Ops ----
+
+func (c *Client) ZAdd(_ context.Context, key string, members ...kvdb.ZMember) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupOrCreate(key, TypeZSet)
+	if err != nil {
+		return 0, err
+	}
+	var added int64
+	for _, m := range members {
+		if _, exists := it.zset[m.Member]; !exists {
+			added++
+		}
+		it.zset[m.Member] = m.Score
+	}
+	c.removeIfEmpty(key, it)
+	return added, nil
+}
+
+// ZRangeByScore returns members ordered by score, then by member lexicographically as Redis does
+func (c *Client) ZRangeByScore(_ context.Context, key string, min float64, max float64, limit int64) ([]kvdb.ZMember, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeZSet)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]kvdb.ZMember, 0)
+	if it == nil {
+		return members, nil
+	}
+	for member, score := range it.zset {
+		if score >= min && score <= max {
+			members = append(members, kvdb.ZMember{Score: score, Member: member})
+		}
+	}
+	slices.SortFunc(members, func(a, b kvdb.ZMember) int {
+		if n := cmp.Compare(a.Score, b.Score); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.Member, b.Member)
+	})
+	if limit > 0 && int64(len(members)) > limit {
+		members = members[:limit]
+	}
+	return members, nil
+}
+
+func (c *Client) ZRem(_ context.Context, key string, members ...string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeZSet)
+	if err != nil || it == nil {
+		return 0, err
+	}
+	var removed int64
+	for _, member := range members {
+		if _, exists := it.zset[member]; exists {
+			delete(it.zset, member)
+			removed++
+		}
+	}
+	c.removeIfEmpty(key, it)
+	return removed, nil
+}
+
+func (c *Client) ZCard(_ context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeZSet)
+	if err != nil || it == nil {
+		return 0, err
+	}
+	return int64(len(it.zset)), nil
+}
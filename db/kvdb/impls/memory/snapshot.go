@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type snapshotItem struct {
+	Type     string             `json:"type"`
+	Str      string             `json:"str,omitempty"`
+	List     []string           `json:"list,omitempty"`
+	Hash     map[string]string  `json:"hash,omitempty"`
+	Set      []string           `json:"set,omitempty"`
+	ZSet     map[string]float64 `json:"zset,omitempty"`
+	ExpireAt int64              `json:"expire_at,omitempty"` // unix milliseconds. 0 = no expiration
+}
+
+// SaveSnapshot writes all live keys to Conf.SnapshotPath atomically (temp file + rename)
+func (c *Client) SaveSnapshot() error {
+	if c.Conf.SnapshotPath == "" {
+		return errors.New("memory kvdb: snapshot path not set")
+	}
+	now := time.Now()
+	c.mu.Lock()
+	snapshot := make(map[string]snapshotItem, len(c.items))
+	for key, it := range c.items {
+		if it.expired(now) {
+			continue
+		}
+		si := snapshotItem{Type: it.kind, Str: it.str}
+		if it.list != nil {
+			si.List = append([]string(nil), it.list...)
+		}
+		if it.hash != nil {
+			si.Hash = make(map[string]string, len(it.hash))
+			for field, val := range it.hash {
+				si.Hash[field] = val
+			}
+		}
+		for member := range it.set {
+			si.Set = append(si.Set, member)
+		}
+		if it.zset != nil {
+			si.ZSet = make(map[string]float64, len(it.zset))
+			for member, score := range it.zset {
+				si.ZSet[member] = score
+			}
+		}
+		if !it.expireAt.IsZero() {
+			si.ExpireAt = it.expireAt.UnixMilli()
+		}
+		snapshot[key] = si
+	}
+	c.mu.Unlock()
+
+	snapshotBytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.Conf.SnapshotPath), ".kvdb-snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // no-op after rename
+	if _, err = tmp.Write(snapshotBytes); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), c.Conf.SnapshotPath); err != nil {
+		return err
+	}
+	log.Printf("[INFO] memory kvdb snapshot saved: %d keys", len(snapshot))
+	return nil
+}
+
+// loadSnapshot restores keys from Conf.SnapshotPath if it exists
+func (c *Client) loadSnapshot() error {
+	snapshotBytes, err := os.ReadFile(c.Conf.SnapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var snapshot map[string]snapshotItem
+	if err = json.Unmarshal(snapshotBytes, &snapshot); err != nil {
+		return fmt.Errorf("memory kvdb: invalid snapshot %s: %w", c.Conf.SnapshotPath, err)
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, si := range snapshot {
+		it := newItem(si.Type)
+		switch si.Type {
+		case TypeString:
+			it.str = si.Str
+		case TypeList:
+			it.list = si.List
+		case TypeHash:
+			for field, val := range si.Hash {
+				it.hash[field] = val
+			}
+		case TypeSet:
+			for _, member := range si.Set {
+				it.set[member] = struct{}{}
+			}
+		case TypeZSet:
+			for member, score := range si.ZSet {
+				it.zset[member] = score
+			}
+		default:
+			continue
+		}
+		if si.ExpireAt > 0 {
+			it.expireAt = time.UnixMilli(si.ExpireAt)
+		}
+		if it.expired(now) || it.empty() {
+			continue
+		}
+		c.items[key] = it
+	}
+	log.Printf("[INFO] memory kvdb snapshot loaded: %d keys", len(c.items))
+	return nil
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+//---- Rate Limit Ops ----
+
+// Throttle evaluates GCRA on the theoretical arrival time (TAT) stored in key as unix microseconds
+func (c *Client) Throttle(_ context.Context, key string, burst int64, emission time.Duration, cost int64, maxDelay time.Duration) (kvdb.ThrottleResult, error) {
+	if err := kvdb.CheckThrottleArgs(burst, emission, cost, maxDelay); err != nil {
+		return kvdb.ThrottleResult{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	it, err := c.lookupType(key, TypeString)
+	if err != nil {
+		return kvdb.ThrottleResult{}, err
+	}
+	now := time.Now()
+	tat := now
+	if it != nil {
+		micros, err := strconv.ParseInt(it.str, 10, 64)
+		if err != nil {
+			return kvdb.ThrottleResult{}, kvdb.ErrNotInteger
+		}
+		tat = time.UnixMicro(micros)
+	}
+	if tat.Before(now) {
+		tat = now
+	}
+	window := time.Duration(burst) * emission
+	newTAT := tat.Add(time.Duration(cost) * emission)
+	delay := newTAT.Add(-window).Sub(now)
+	if delay > maxDelay {
+		return kvdb.ThrottleResult{
+			Remaining:  remainingTokens(now, tat, burst, emission),
+			RetryAfter: delay,
+			ResetAfter: tat.Sub(now),
+		}, nil
+	}
+	switch {
+	case !newTAT.After(now): // all given back
+		delete(c.items, key)
+	case cost != 0:
+		c.items[key] = &item{kind: TypeString, str: strconv.FormatInt(newTAT.UnixMicro(), 10), expireAt: newTAT}
+	}
+	return kvdb.ThrottleResult{
+		Allowed:    true,
+		Remaining:  remainingTokens(now, newTAT, burst, emission),
+		RetryAfter: max(delay, 0),
+		ResetAfter: max(newTAT.Sub(now), 0),
+	}, nil
+}
+
+// remainingTokens is the number of emissions between now and the earliest time tat allows, up to burst
+func remainingTokens(now time.Time, tat time.Time, burst int64, emission time.Duration) int64 {
+	window := time.Duration(burst) * emission
+	return min(max(int64(now.Sub(tat.Add(-window))/emission), 0), burst)
+}
@@ -0,0 +1,322 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+
+	lowimpl "github.com/redis/go-redis/v9"
+)
+
+// batch queues commands on a lowimpl.Pipeliner and resolves the Results from its Cmds after Exec
+type batch struct {
+	pipe      lowimpl.Pipeliner
+	resolvers []func()
+}
+
+// Ensure batch implements kvdb.Batch interface
+var _ kvdb.Batch = (*batch)(nil)
+
+func (c *Client) Pipeline() kvdb.Batch {
+	return &batch{pipe: c.internal.Pipeline()}
+}
+
+func (c *Client) TxPipeline() kvdb.Batch {
+	return &batch{pipe: c.internal.TxPipeline()}
+}
+
+func (c *Client) Watch(ctx context.Context, fn func(tx kvdb.Tx) error, keys ...string) error {
+	err := c.internal.Watch(ctx, func(t *lowimpl.Tx) error {
+		return fn(&tx{internal: t})
+	}, keys...)
+	return convertErr(err)
+}
+
+type tx struct {
+	internal *lowimpl.Tx
+}
+
+func (t *tx) TxPipeline() kvdb.Batch {
+	return &batch{pipe: t.internal.TxPipeline()}
+}
+
+func (b *batch) Queued() int {
+	return len(b.resolvers)
+}
+
+func (b *batch) Exec(ctx context.Context) error {
+	cmds, err := b.pipe.Exec(ctx)
+	for _, resolve := range b.resolvers {
+		resolve()
+	}
+	b.resolvers = nil
+	if err == nil {
+		return nil
+	}
+	// Exec reports the first failed Cmd, which can be a not-found (redis.Nil)
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, lowimpl.Nil) {
+			return convertErr(cmdErr)
+		}
+	}
+	if errors.Is(err, lowimpl.Nil) {
+		return nil
+	}
+	return convertErr(err)
+}
+
+// queue registers a resolver of a new Result, called after Exec
+func queue[T any](b *batch, resolve func() (T, bool, error)) *kvdb.Result[T] {
+	r := kvdb.NewResult[T]()
+	b.resolvers = append(b.resolvers, func() {
+		r.Resolve(resolve())
+	})
+	return r
+}
+
+// succeeded adapts (val, err) results to (val, found, err). found = succeeded
+func succeeded[T any](val T, err error) (T, bool, error) {
+	err = convertErr(err)
+	return val, err == nil, err
+}
+
+// found adapts (val, err) results of commands replying redis.Nil when not found
+func found[T any](val T, err error) (T, bool, error) {
+	if errors.Is(err, lowimpl.Nil) {
+		return val, false, nil
+	}
+	err = convertErr(err)
+	return val, err == nil, err
+}
+
+func status(err error) (struct{}, bool, error) {
+	err = convertErr(err)
+	return struct{}{}, err == nil, err
+}
+
+// bg is for queueing. go-redis uses the context passed to Exec for the round trip
+var bg = context.Background()
+
+//---- Key Ops ----
+
+func (b *batch) Exists(key string) *kvdb.Result[bool] {
+	cmd := b.pipe.Exists(bg, key)
+	return queue(b, func() (bool, bool, error) {
+		n, err := cmd.Result()
+		return succeeded(n > 0, err)
+	})
+}
+
+func (b *batch) Delete(keys ...string) *kvdb.Result[int64] {
+	cmd := b.pipe.Del(bg, keys...)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) Expire(key string, expiration time.Duration) *kvdb.Result[bool] {
+	cmd := b.pipe.Expire(bg, key, expiration)
+	return queue(b, func() (bool, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) TTL(key string) *kvdb.Result[time.Duration] {
+	cmd := b.pipe.TTL(bg, key)
+	return queue(b, func() (time.Duration, bool, error) {
+		ttl, err := cmd.Result()
+		if err != nil {
+			return 0, false, convertErr(err)
+		}
+		switch ttl {
+		case -2:
+			return 0, false, nil
+		case -1:
+			return kvdb.NoExpiration, true, nil
+		}
+		return ttl, true, nil
+	})
+}
+
+func (b *batch) Persist(key string) *kvdb.Result[bool] {
+	cmd := b.pipe.Persist(bg, key)
+	return queue(b, func() (bool, bool, error) { return succeeded(cmd.Result()) })
+}
+
+//---- Single-value Ops ----
+
+func (b *batch) Set(key string, value any, expiration time.Duration) *kvdb.Result[struct{}] {
+	cmd := b.pipe.Set(bg, key, value, expiration)
+	return queue(b, func() (struct{}, bool, error) { return status(cmd.Err()) })
+}
+
+func (b *batch) Get(key string) *kvdb.Result[string] {
+	cmd := b.pipe.Get(bg, key)
+	return queue(b, func() (string, bool, error) { return found(cmd.Result()) })
+}
+
+func (b *batch) SetNX(key string, value any, expiration time.Duration) *kvdb.Result[bool] {
+	cmd := b.pipe.SetNX(bg, key, value, expiration)
+	return queue(b, func() (bool, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) SetXX(key string, value any, expiration time.Duration) *kvdb.Result[bool] {
+	cmd := b.pipe.SetXX(bg, key, value, expiration)
+	return queue(b, func() (bool, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) GetDel(key string) *kvdb.Result[string] {
+	cmd := b.pipe.GetDel(bg, key)
+	return queue(b, func() (string, bool, error) { return found(cmd.Result()) })
+}
+
+//---- Counter Ops ----
+
+func (b *batch) Incr(key string) *kvdb.Result[int64] {
+	cmd := b.pipe.Incr(bg, key)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) IncrBy(key string, n int64) *kvdb.Result[int64] {
+	cmd := b.pipe.IncrBy(bg, key, n)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) Decr(key string) *kvdb.Result[int64] {
+	cmd := b.pipe.Decr(bg, key)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+//---- List Ops ----
+
+func (b *batch) Push(key string, value string) *kvdb.Result[struct{}] {
+	cmd := b.pipe.RPush(bg, key, value)
+	return queue(b, func() (struct{}, bool, error) { return status(cmd.Err()) })
+}
+
+func (b *batch) Pop(key string) *kvdb.Result[string] {
+	cmd := b.pipe.LPop(bg, key)
+	return queue(b, func() (string, bool, error) { return found(cmd.Result()) })
+}
+
+func (b *batch) PopPush(src string, dst string) *kvdb.Result[string] {
+	cmd := b.pipe.LMove(bg, src, dst, "LEFT", "RIGHT")
+	return queue(b, func() (string, bool, error) { return found(cmd.Result()) })
+}
+
+func (b *batch) Len(key string) *kvdb.Result[int64] {
+	cmd := b.pipe.LLen(bg, key)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) Range(key string, start int64, stop int64) *kvdb.Result[[]string] {
+	cmd := b.pipe.LRange(bg, key, start, stop)
+	return queue(b, func() ([]string, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) Remove(key string, cnt int64, value any) *kvdb.Result[int64] {
+	cmd := b.pipe.LRem(bg, key, cnt, value)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) Trim(key string, start int64, stop int64) *kvdb.Result[struct{}] {
+	cmd := b.pipe.LTrim(bg, key, start, stop)
+	return queue(b, func() (struct{}, bool, error) { return status(cmd.Err()) })
+}
+
+//---- Hash Ops ----
+
+func (b *batch) SetField(key string, field string, value any) *kvdb.Result[struct{}] {
+	cmd := b.pipe.HSet(bg, key, field, value)
+	return queue(b, func() (struct{}, bool, error) { return status(cmd.Err()) })
+}
+
+func (b *batch) GetField(key string, field string) *kvdb.Result[string] {
+	cmd := b.pipe.HGet(bg, key, field)
+	return queue(b, func() (string, bool, error) { return found(cmd.Result()) })
+}
+
+func (b *batch) SetFields(key string, fields map[string]any) *kvdb.Result[struct{}] {
+	cmd := b.pipe.HSet(bg, key, fields)
+	return queue(b, func() (struct{}, bool, error) { return status(cmd.Err()) })
+}
+
+func (b *batch) GetFields(key string, fields ...string) *kvdb.Result[map[string]string] {
+	cmd := b.pipe.HMGet(bg, key, fields...)
+	return queue(b, func() (map[string]string, bool, error) {
+		resultSlice, err := cmd.Result()
+		if err != nil {
+			return succeeded[map[string]string](nil, err)
+		}
+		rtnMap := make(map[string]string, len(fields))
+		for i, v := range resultSlice {
+			if v != nil {
+				rtnMap[fields[i]] = fmt.Sprint(v)
+			}
+		}
+		return rtnMap, true, nil
+	})
+}
+
+func (b *batch) RemoveFields(key string, fields ...string) *kvdb.Result[int64] {
+	cmd := b.pipe.HDel(bg, key, fields...)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) GetAllFields(key string) *kvdb.Result[map[string]string] {
+	cmd := b.pipe.HGetAll(bg, key)
+	return queue(b, func() (map[string]string, bool, error) { return succeeded(cmd.Result()) })
+}
+
+//---- Set Ops ----
+
+func (b *batch) SAdd(key string, members ...any) *kvdb.Result[int64] {
+	cmd := b.pipe.SAdd(bg, key, members...)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) SRem(key string, members ...any) *kvdb.Result[int64] {
+	cmd := b.pipe.SRem(bg, key, members...)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) SMembers(key string) *kvdb.Result[[]string] {
+	cmd := b.pipe.SMembers(bg, key)
+	return queue(b, func() ([]string, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) SIsMember(key string, member any) *kvdb.Result[bool] {
+	cmd := b.pipe.SIsMember(bg, key, member)
+	return queue(b, func() (bool, bool, error) { return succeeded(cmd.Result()) })
+}
+
+//---- Sorted Set Ops ----
+
+func (b *batch) ZAdd(key string, members ...kvdb.ZMember) *kvdb.Result[int64] {
+	cmd := b.pipe.ZAdd(bg, key, toZs(members)...)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) ZRangeByScore(key string, min float64, max float64, limit int64) *kvdb.Result[[]kvdb.ZMember] {
+	cmd := b.pipe.ZRangeByScoreWithScores(bg, key, &lowimpl.ZRangeBy{
+		Min:   formatScore(min),
+		Max:   formatScore(max),
+		Count: limit,
+	})
+	return queue(b, func() ([]kvdb.ZMember, bool, error) {
+		zs, err := cmd.Result()
+		if err != nil {
+			return succeeded[[]kvdb.ZMember](nil, err)
+		}
+		return fromZs(zs), true, nil
+	})
+}
+
+func (b *batch) ZRem(key string, members ...string) *kvdb.Result[int64] {
+	cmd := b.pipe.ZRem(bg, key, toArgs(members)...)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
+
+func (b *batch) ZCard(key string) *kvdb.Result[int64] {
+	cmd := b.pipe.ZCard(bg, key)
+	return queue(b, func() (int64, bool, error) { return succeeded(cmd.Result()) })
+}
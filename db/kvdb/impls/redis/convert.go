@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+
+	lowimpl "github.com/redis/go-redis/v9"
+)
+
+// convertErr maps Redis error replies to kvdb errors where the callers may branch on them
+func convertErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, lowimpl.TxFailedErr) {
+		return kvdb.ErrTxFailed
+	}
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "WRONGTYPE"):
+		return kvdb.ErrWrongType
+	case strings.HasPrefix(msg, "ERR value is not an integer"):
+		return kvdb.ErrNotInteger
+	}
+	return err
+}
+
+// formatScore formats a sorted set score bound. infinities as -inf/+inf
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, 1):
+		return "+inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+func toZs(members []kvdb.ZMember) []lowimpl.Z {
+	zs := make([]lowimpl.Z, len(members))
+	for i, m := range members {
+		zs[i] = lowimpl.Z{Score: m.Score, Member: m.Member}
+	}
+	return zs
+}
+
+func fromZs(zs []lowimpl.Z) []kvdb.ZMember {
+	members := make([]kvdb.ZMember, len(zs))
+	for i, z := range zs {
+		members[i] = kvdb.ZMember{Score: z.Score, Member: fmt.Sprint(z.Member)}
+	}
+	return members
+}
+
+func toArgs(strs []string) []any {
+	args := make([]any, len(strs))
+	for i, s := range strs {
+		args[i] = s
+	}
+	return args
+}
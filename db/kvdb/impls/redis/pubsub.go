@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"context"
+	"log"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+
+	lowimpl "github.com/redis/go-redis/v9"
+)
+
+func (c *Client) Publish(ctx context.Context, channel string, payload any) (int64, error) {
+	return c.internal.Publish(ctx, channel, payload).Result()
+}
+
+// Subscribe uses a lowimpl.PubSub on a dedicated connection.
+// Its message channel checks the connection health periodically, reconnects and resubscribes to all channels and patterns
+func (c *Client) Subscribe(ctx context.Context, channels ...string) (<-chan kvdb.Message, error) {
+	var names, patterns []string
+	for _, ch := range channels {
+		if kvdb.IsPattern(ch) {
+			patterns = append(patterns, ch)
+		} else {
+			names = append(names, ch)
+		}
+	}
+	ps := c.internal.Subscribe(ctx) // no channels yet. subscribe below to get the errors
+	if len(names) > 0 {
+		if err := ps.Subscribe(ctx, names...); err != nil {
+			_ = ps.Close()
+			return nil, err
+		}
+	}
+	if len(patterns) > 0 {
+		if err := ps.PSubscribe(ctx, patterns...); err != nil {
+			_ = ps.Close()
+			return nil, err
+		}
+	}
+	c.subsMu.Lock()
+	if c.subs == nil {
+		c.subs = make(map[*lowimpl.PubSub]struct{})
+	}
+	c.subs[ps] = struct{}{}
+	c.subsMu.Unlock()
+
+	msgCh := make(chan kvdb.Message)
+	go func() {
+		defer close(msgCh)
+		defer c.closeSubscription(ps)
+		internalCh := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-internalCh:
+				if !ok { // PubSub closed by Client.Close
+					return
+				}
+				select {
+				case msgCh <- kvdb.Message{Channel: msg.Channel, Pattern: msg.Pattern, Payload: msg.Payload}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return msgCh, nil
+}
+
+func (c *Client) closeSubscription(ps *lowimpl.PubSub) {
+	c.subsMu.Lock()
+	_, active := c.subs[ps]
+	delete(c.subs, ps)
+	c.subsMu.Unlock()
+	if !active { // closed by closeSubscriptions already
+		return
+	}
+	if err := ps.Close(); err != nil {
+		log.Printf("[WARN] redis subscription close failed: %v", err)
+	}
+}
+
+// closeSubscriptions closes all PubSubs so that the subscribers' channels are closed
+func (c *Client) closeSubscriptions() {
+	c.subsMu.Lock()
+	subs := c.subs
+	c.subs = nil
+	c.subsMu.Unlock()
+	for ps := range subs {
+		_ = ps.Close()
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/zeptools/gw-core/db/kvdb"
@@ -17,11 +18,17 @@ type Client struct {
 
 	// implementation details, not exported
 	internal *lowimpl.Client
+	subsMu   sync.Mutex
+	subs     map[*lowimpl.PubSub]struct{} // active subscriptions. closed on Close
 }
 
 // Ensure redis.Client implements kvdb.Client interface
 var _ kvdb.Client = (*Client)(nil)
 
+func NewClient(conf *kvdb.Conf) (kvdb.Client, error) {
+	return &Client{Conf: conf}, nil
+}
+
 func (c *Client) Init() error {
 	c.internal = lowimpl.NewClient(&lowimpl.Options{
 		Addr:     fmt.Sprintf("%s:%d", c.Conf.Host, c.Conf.Port),
@@ -36,9 +43,14 @@ func (c *Client) Close() error {
 	if c.internal == nil {
 		return nil
 	}
+	c.closeSubscriptions()
 	return c.internal.Close()
 }
 
+func (c *Client) Ping(ctx context.Context) error {
+	return c.internal.Ping(ctx).Err()
+}
+
 func (c *Client) GetHandle() any { // use with runtime type assertion
 	return c.internal
 }
@@ -63,16 +75,38 @@ func (c *Client) Expire(ctx context.Context, key string, expiration time.Duratio
 	return c.internal.Expire(ctx, key, expiration).Result()
 }
 
+func (c *Client) TTL(ctx context.Context, key string) (time.Duration, bool, error) {
+	ttl, err := c.internal.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, false, err
+	}
+	// Redis TTL returns -2 if key does not exist, -1 if key has no expiration
+	switch ttl {
+	case -2:
+		return 0, false, nil
+	case -1:
+		return kvdb.NoExpiration, true, nil
+	}
+	return ttl, true, nil
+}
+
+func (c *Client) Persist(ctx context.Context, key string) (bool, error) {
+	return c.internal.Persist(ctx, key).Result()
+}
+
 func (c *Client) Type(ctx context.Context, key string) (string, error) {
 	return c.internal.Type(ctx, key).Result()
 }
 
-func (c *Client) ScanKeys(ctx context.Context, cursor any, scanBatchSize int) ([]string, any, error) {
+func (c *Client) ScanKeys(ctx context.Context, cursor any, match string, scanBatchSize int) ([]string, any, error) {
 	var cur uint64
 	if cursor != nil {
 		cur = cursor.(uint64)
 	}
-	keys, nextCursor, err := c.internal.Scan(ctx, cur, "*", int64(scanBatchSize)).Result()
+	if match == "" {
+		match = "*"
+	}
+	keys, nextCursor, err := c.internal.Scan(ctx, cur, match, int64(scanBatchSize)).Result()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -100,6 +134,42 @@ func (c *Client) Set(ctx context.Context, key string, value any, expiration time
 	return c.internal.Set(ctx, key, value, expiration).Err()
 }
 
+func (c *Client) SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	return c.internal.SetNX(ctx, key, value, expiration).Result()
+}
+
+func (c *Client) SetXX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error) {
+	return c.internal.SetXX(ctx, key, value, expiration).Result()
+}
+
+func (c *Client) GetDel(ctx context.Context, key string) (string, bool, error) {
+	val, err := c.internal.GetDel(ctx, key).Result()
+	if errors.Is(err, lowimpl.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+//---- Counter Ops ----
+
+func (c *Client) Incr(ctx context.Context, key string) (int64, error) {
+	n, err := c.internal.Incr(ctx, key).Result()
+	return n, convertErr(err)
+}
+
+func (c *Client) IncrBy(ctx context.Context, key string, n int64) (int64, error) {
+	n, err := c.internal.IncrBy(ctx, key, n).Result()
+	return n, convertErr(err)
+}
+
+func (c *Client) Decr(ctx context.Context, key string) (int64, error) {
+	n, err := c.internal.Decr(ctx, key).Result()
+	return n, convertErr(err)
+}
+
 //---- List Ops ----
 
 func (c *Client) Push(ctx context.Context, key, value string) error {
@@ -119,6 +189,17 @@ func (c *Client) Pop(ctx context.Context, key string) (string, bool, error) { //
 	return val, true, nil
 }
 
+func (c *Client) PopPush(ctx context.Context, src string, dst string) (string, bool, error) {
+	val, err := c.internal.LMove(ctx, src, dst, "LEFT", "RIGHT").Result()
+	if errors.Is(err, lowimpl.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, convertErr(err)
+	}
+	return val, true, nil
+}
+
 func (c *Client) Len(ctx context.Context, key string) (int64, error) {
 	return c.internal.LLen(ctx, key).Result()
 }
@@ -183,3 +264,47 @@ func (c *Client) RemoveFields(ctx context.Context, key string, fields ...string)
 func (c *Client) GetAllFields(ctx context.Context, key string) (map[string]string, error) {
 	return c.internal.HGetAll(ctx, key).Result()
 }
+
+//---- Set Ops ----
+
+func (c *Client) SAdd(ctx context.Context, key string, members ...any) (int64, error) {
+	return c.internal.SAdd(ctx, key, members...).Result()
+}
+
+func (c *Client) SRem(ctx context.Context, key string, members ...any) (int64, error) {
+	return c.internal.SRem(ctx, key, members...).Result()
+}
+
+func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	return c.internal.SMembers(ctx, key).Result()
+}
+
+func (c *Client) SIsMember(ctx context.Context, key string, member any) (bool, error) {
+	return c.internal.SIsMember(ctx, key, member).Result()
+}
+
+//---- Sorted Set Ops ----
+
+func (c *Client) ZAdd(ctx context.Context, key string, members ...kvdb.ZMember) (int64, error) {
+	return c.internal.ZAdd(ctx, key, toZs(members)...).Result()
+}
+
+func (c *Client) ZRangeByScore(ctx context.Context, key string, min float64, max float64, limit int64) ([]kvdb.ZMember, error) {
+	zs, err := c.internal.ZRangeByScoreWithScores(ctx, key, &lowimpl.ZRangeBy{
+		Min:   formatScore(min),
+		Max:   formatScore(max),
+		Count: limit, // 0 = no LIMIT
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	return fromZs(zs), nil
+}
+
+func (c *Client) ZRem(ctx context.Context, key string, members ...string) (int64, error) {
+	return c.internal.ZRem(ctx, key, toArgs(members)...).Result()
+}
+
+func (c *Client) ZCard(ctx context.Context, key string) (int64, error) {
+	return c.internal.ZCard(ctx, key).Result()
+}
@@ -0,0 +1,7 @@
+package redis
+
+import "github.com/zeptools/gw-core/db/kvdb"
+
+func Register() {
+	kvdb.RegisterFactory(DBType, NewClient)
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+
+	lowimpl "github.com/redis/go-redis/v9"
+)
+
+// throttleScript evaluates GCRA on the theoretical arrival time (TAT) stored in KEYS[1] as unix microseconds
+// on the clock of the server, so that the instances need not agree on the time.
+// ARGV: burst, emission, cost, max delay. durations in microseconds
+// Returns {allowed, remaining, retry_after, reset_after} in microseconds
+var throttleScript = lowimpl.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local burst = tonumber(ARGV[1])
+local emission = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local max_delay = tonumber(ARGV[4])
+local window = burst * emission
+local tat = tonumber(redis.call('GET', KEYS[1])) or now
+if tat < now then
+	tat = now
+end
+local function remaining(at)
+	return math.min(math.max(math.floor((now - at + window) / emission), 0), burst)
+end
+local new_tat = tat + cost * emission
+local delay = new_tat - window - now
+if delay > max_delay then
+	return {0, remaining(tat), delay, tat - now}
+end
+if new_tat <= now then
+	redis.call('DEL', KEYS[1])
+elseif cost ~= 0 then
+	redis.call('SET', KEYS[1], string.format('%d', new_tat), 'PX', math.max(math.ceil((new_tat - now) / 1000), 1))
+end
+return {1, remaining(new_tat), math.max(delay, 0), math.max(new_tat - now, 0)}
+`)
+
+//---- Rate Limit Ops ----
+
+func (c *Client) Throttle(ctx context.Context, key string, burst int64, emission time.Duration, cost int64, maxDelay time.Duration) (kvdb.ThrottleResult, error) {
+	if err := kvdb.CheckThrottleArgs(burst, emission, cost, maxDelay); err != nil {
+		return kvdb.ThrottleResult{}, err
+	}
+	vals, err := throttleScript.Run(ctx, c.internal, []string{key}, burst, emission.Microseconds(), cost, maxDelay.Microseconds()).Int64Slice()
+	if err != nil {
+		return kvdb.ThrottleResult{}, convertErr(err)
+	}
+	return toThrottleResult(vals)
+}
+
+func (b *batch) Throttle(key string, burst int64, emission time.Duration, cost int64, maxDelay time.Duration) *kvdb.Result[kvdb.ThrottleResult] {
+	if err := kvdb.CheckThrottleArgs(burst, emission, cost, maxDelay); err != nil {
+		return queue(b, func() (kvdb.ThrottleResult, bool, error) { return kvdb.ThrottleResult{}, false, err })
+	}
+	// EVAL, not EVALSHA, since a NOSCRIPT error cannot be retried within the batch
+	cmd := throttleScript.Eval(bg, b.pipe, []string{key}, burst, emission.Microseconds(), cost, maxDelay.Microseconds())
+	return queue(b, func() (kvdb.ThrottleResult, bool, error) {
+		vals, err := cmd.Int64Slice()
+		if err != nil {
+			err = convertErr(err)
+			return kvdb.ThrottleResult{}, false, err
+		}
+		return succeeded(toThrottleResult(vals))
+	})
+}
+
+func toThrottleResult(vals []int64) (kvdb.ThrottleResult, error) {
+	if len(vals) != 4 {
+		return kvdb.ThrottleResult{}, fmt.Errorf("redis throttle: unexpected reply %v", vals)
+	}
+	return kvdb.ThrottleResult{
+		Allowed:    vals[0] == 1,
+		Remaining:  vals[1],
+		RetryAfter: time.Duration(vals[2]) * time.Microsecond,
+		ResetAfter: time.Duration(vals[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package kvdb
+
+import "strings"
+
+// MatchPattern reports whether key matches a Redis-style glob pattern
+//
+//	pattern  matches
+//	*        any sequence of characters (including '/')
+//	?        any single character
+//	[abc]    one of the characters. [^abc] negated, [a-z] range
+//	\x       the literal x
+//
+// For implementations without native pattern matching
+func MatchPattern(pattern string, key string) bool {
+	return matchRunes([]rune(pattern), []rune(key))
+}
+
+// IsPattern reports whether name contains glob special characters
+func IsPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+func matchRunes(p []rune, k []rune) bool {
+	for len(p) > 0 {
+		switch p[0] {
+		case '*':
+			for len(p) > 1 && p[1] == '*' {
+				p = p[1:]
+			}
+			if len(p) == 1 {
+				return true
+			}
+			for i := 0; i <= len(k); i++ {
+				if matchRunes(p[1:], k[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(k) == 0 {
+				return false
+			}
+			k = k[1:]
+			p = p[1:]
+		case '[':
+			if len(k) == 0 {
+				return false
+			}
+			end := 1
+			negate := end < len(p) && p[end] == '^'
+			if negate {
+				end++
+			}
+			matched := false
+			for end < len(p) && p[end] != ']' {
+				switch {
+				case p[end] == '\\' && end+1 < len(p):
+					end++
+					matched = matched || p[end] == k[0]
+					end++
+				case end+2 < len(p) && p[end+1] == '-' && p[end+2] != ']':
+					lo, hi := p[end], p[end+2]
+					if lo > hi {
+						lo, hi = hi, lo
+					}
+					matched = matched || (k[0] >= lo && k[0] <= hi)
+					end += 3
+				default:
+					matched = matched || p[end] == k[0]
+					end++
+				}
+			}
+			if end >= len(p) { // unterminated: treat '[' as a literal
+				if k[0] != '[' {
+					return false
+				}
+				k = k[1:]
+				p = p[1:]
+				continue
+			}
+			if matched == negate {
+				return false
+			}
+			k = k[1:]
+			p = p[end+1:]
+		case '\\':
+			if len(p) > 1 {
+				p = p[1:]
+			}
+			fallthrough
+		default:
+			if len(k) == 0 || p[0] != k[0] {
+				return false
+			}
+			k = k[1:]
+			p = p[1:]
+		}
+	}
+	return len(k) == 0
+}
@@ -0,0 +1,8 @@
+package kvdb
+
+// Message is a message received from a subscription
+type Message struct {
+	Channel string // channel name the message was published to
+	Pattern string // matched pattern if received by a pattern subscription. empty otherwise
+	Payload string // message payload
+}
@@ -135,5 +135,5 @@ func (c *Client) BeginTx(ctx context.Context) (sqldb.Tx, error) {
 		conn.Release()
 		return nil, fmt.Errorf("begin transaction failed: %w", err)
 	}
-	return &Tx{tx: tx}, nil
+	return &Tx{tx: tx, conn: conn}, nil
 }
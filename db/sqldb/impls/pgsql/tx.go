@@ -4,24 +4,35 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/zeptools/gw-core/db/sqldb"
 )
 
 type Tx struct {
-	tx pgx.Tx
+	tx   pgx.Tx
+	conn *pgxpool.Conn // acquired by BeginTx. released on Commit or Rollback
 }
 
 // Ensure pgsql.Tx implements sqldb.Tx
 var _ sqldb.Tx = (*Tx)(nil)
 
 func (t *Tx) Commit(ctx context.Context) error {
+	defer t.release()
 	return t.tx.Commit(ctx)
 }
 
 func (t *Tx) Rollback(ctx context.Context) error {
+	defer t.release()
 	return t.tx.Rollback(ctx)
 }
 
+func (t *Tx) release() {
+	if t.conn != nil {
+		t.conn.Release()
+		t.conn = nil
+	}
+}
+
 func (t *Tx) Exec(ctx context.Context, query string, args ...any) (sqldb.Result, error) {
 	tag, err := t.tx.Exec(ctx, query, args...)
 	if err != nil {
@@ -0,0 +1,21 @@
+package framework
+
+import (
+	"fmt"
+
+	"github.com/zeptools/gw-core/cache"
+)
+
+// NewCache returns a cache.Cache on the KV DB client kvdbName (empty = default),
+// namespaced by AppName and name, e.g.
+//
+//	users, err := framework.NewCache[User](core, "users", "", cache.Options{StaleTTL: time.Minute})
+//
+// Prerequisite: PrepareKVDatabases
+func NewCache[T any, B comparable](c *Core[B], name string, kvdbName string, opts cache.Options) (*cache.Cache[T], error) {
+	client, ok := c.GetKVDBClient(kvdbName)
+	if !ok {
+		return nil, fmt.Errorf("kvdb client %q not found", kvdbName)
+	}
+	return cache.New[T](client, c.AppName+"_cache:"+name, opts), nil
+}
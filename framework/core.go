@@ -2,9 +2,11 @@ package framework
 
 import (
 	"context"
+	"encoding/json/jsontext"
 	"encoding/json/v2"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -17,11 +19,18 @@ import (
 
 	"github.com/zeptools/gw-core/apis/mainbackend"
 	"github.com/zeptools/gw-core/clients"
+	"github.com/zeptools/gw-core/configs"
 	"github.com/zeptools/gw-core/db/kvdb"
+	"github.com/zeptools/gw-core/db/kvdb/impls/memory"
 	"github.com/zeptools/gw-core/db/kvdb/impls/redis"
 	"github.com/zeptools/gw-core/db/sqldb"
 	"github.com/zeptools/gw-core/db/sqldb/impls/mysql"
 	"github.com/zeptools/gw-core/db/sqldb/impls/pgsql"
+	"github.com/zeptools/gw-core/health"
+	"github.com/zeptools/gw-core/locks/keymutex"
+	"github.com/zeptools/gw-core/locks/keyonlylocks"
+	"github.com/zeptools/gw-core/locks/kvdblocks"
+	"github.com/zeptools/gw-core/queue"
 	"github.com/zeptools/gw-core/schedjobs"
 	"github.com/zeptools/gw-core/security"
 	"github.com/zeptools/gw-core/storages"
@@ -33,6 +42,9 @@ import (
 	"github.com/zeptools/gw-core/web/session"
 )
 
+// DefaultKVDBName is the name of the default Key-value DB Client in .kv-databases.json
+const DefaultKVDBName = "default"
+
 // Core - common config
 // B = Throttle BucketID Type _ e.g. string, int64, etc
 type Core[B comparable] struct {
@@ -41,95 +53,117 @@ type Core[B comparable] struct {
 	Host                string                                           `json:"host"`       // HTTP Host. Can be used to generate public url endpoints
 	DebugOpts           DebugOpts                                        `json:"debug_opts"` // Debug Options
 	AppRoot             string                                           `json:"-"`          // Filled from compiled paths
+	ConfLoader          *configs.Loader                                  `json:"-"`          // Layered Config Loader. BaseInit
 	RootCtx             context.Context                                  `json:"-"`          // Global Context with RootCancel
 	RootCancel          context.CancelFunc                               `json:"-"`          // CancelFunc for RootCtx
+	ServiceCtx          context.Context                                  `json:"-"`          // Parent Context for Managed Services. Not cancelled with RootCtx; stopped in order
 	UDSService          *uds.Service                                     `json:"-"`          // PrepareUDSService
 	JobScheduler        *schedjobs.Scheduler                             `json:"-"`          // PrepareJobScheduler
 	WebService          *web.Service                                     `json:"-"`          // PrepareWebService
 	ThrottleBucketStore *throttle.BucketStore[B]                         `json:"-"`          // PrepareThrottleBucketStore
 	VolatileKV          *sync.Map                                        `json:"-"`          // map[string]string
-	SessionLocks        *sync.Map                                        `json:"-"`          // map[string]*sync.Mutex for ServiceSessions and WebSessions
-	ActionLocks         *sync.Map                                        `json:"-"`          // map[string]struct{}
+	SessionLocks        *keymutex.Mutex                                  `json:"-"`          // per-user locks for ServiceSessions and WebSessions
+	ActionLocks         *keyonlylocks.Manager                            `json:"-"`          // Locks in this instance
 	StorageConf         storages.Conf                                    `json:"-"`          // LoadStorageConf
 	BackendHttpClient   *http.Client                                     `json:"-"`          // for requests to external apis
-	KVDBConf            kvdb.Conf                                        `json:"-"`          // loadKVDBConf
-	BackendKVDBClient   kvdb.Client                                      `json:"-"`          // prepareKVDBClient
+	KVDBConfs           map[string]*kvdb.Conf                            `json:"-"`          // loadKVDBConfs
+	BackendKVDBClients  map[string]kvdb.Client                           `json:"-"`          // prepareKVDBClients
+	BackendKVDBClient   kvdb.Client                                      `json:"-"`          // Default of BackendKVDBClients. prepareKVDBClients
 	SQLDBConfs          map[string]*sqldb.Conf                           `json:"-"`          // loadSQLDBConfs
 	BackendSQLDBClients map[string]sqldb.Client                          `json:"-"`          // prepareSQLDBClients
 	ClientApps          atomic.Pointer[map[string]clients.ClientAppConf] `json:"-"`          // [Hot Reload] PrepareClientApps
-	WebSessionManager   *session.Manager                                 `json:"-"`          // PrepareWebSessions
-	MainBackendClient   *mainbackend.Client                              `json:"-"`          // PrepareMainBackendClient
-	HTMLTemplateStore   *tpl.HTMLTemplateStore                           `json:"-"`          // PrepareHTMLTemplateStore
-
-	services []svc.Service // Services to Manage
-	done     chan error
+	WebSessionManager   *session.Manager                                 `json:"-"`          // Deprecated: use GetWebSessionManager. Not swapped by Reload
+	MainBackendClient   *mainbackend.Client                              `json:"-"`          // Deprecated: use GetMainBackendClient. Not swapped by Reload
+	HTMLTemplateStore   *tpl.HTMLTemplateStore                           `json:"-"`          // Deprecated: use GetHTMLTemplateStore. Not swapped by Reload
+	HealthRegistry      *health.Registry                                 `json:"-"`          // PrepareHealthChecks
+	Queues              map[string]*queue.Queue                          `json:"-"`          // PrepareQueue
+	DistLocker          *kvdblocks.Locker                                `json:"-"`          // PrepareDistLocker. Locks across instances
+
+	services  *svc.Manager // Services to Manage
+	reloaders []reloader   // Hot Reload Targets. registered by Prepare* methods
+	reloadMu  sync.Mutex   // serializes Reload
+
+	webSessionManager atomic.Pointer[session.Manager]       // [Hot Reload] PrepareWebSessions
+	mainBackendClient atomic.Pointer[mainbackend.Client]    // [Hot Reload] PrepareMainBackendClient
+	htmlTemplateStore atomic.Pointer[tpl.HTMLTemplateStore] // [Hot Reload] PrepareHTMLTemplateStore
 }
 
 // BaseInit - 1st step for initialization
 // 1. set AppRoot
-// 2. load config/.core.json file
+// 2. prepare ConfLoader & load config/.core.json file
 // 3. prepare base fields
 // 4. Start ShutdownSignalListener
 func (c *Core[B]) BaseInit(appRoot string, rootCtx context.Context, rootCancel context.CancelFunc) error {
 	c.AppRoot = appRoot
-	// Load .env.json
-	envFilePath := filepath.Join(appRoot, "config", ".core.json")
-	//file, readErr := os.Open(envFilePath) // (*os.File, error)
-	envBytes, err := os.ReadFile(envFilePath) // ([]byte, error)
-	if err != nil {
-		return err
+	c.ConfLoader = configs.NewLoader(filepath.Join(appRoot, "config"))
+	if c.ConfLoader.Env != "" {
+		log.Printf("[INFO][CORE] config environment: %s", c.ConfLoader.Env)
 	}
-	if err = json.Unmarshal(envBytes, c); err != nil {
+	if err := c.ConfLoader.Load(".core.json", "CORE", c); err != nil {
 		return err
 	}
 	c.RootCtx = rootCtx
 	c.RootCancel = rootCancel
+	c.ServiceCtx = context.WithoutCancel(rootCtx) // services are stopped by the manager in reverse dependency order
+	c.services = svc.NewManager(rootCtx)
 	c.prepareDefaultFeatures()
 	c.startShutdownSignalListener()
+	c.startReloadSignalListener()
 	return nil
 }
 
 func (c *Core[B]) prepareDefaultFeatures() {
 	c.VolatileKV = &sync.Map{}
-	c.SessionLocks = &sync.Map{}
+	c.SessionLocks = keymutex.New()
 	c.BackendHttpClient = &http.Client{}
-	c.ActionLocks = &sync.Map{}
+	c.ActionLocks = keyonlylocks.NewManager()
 }
 
-func (c *Core[B]) AddService(s svc.Service) {
-	log.Printf("[INFO] adding service: %s", s.Name())
-	c.services = append(c.services, s)
-	log.Printf("[INFO] total services: %d", len(c.services))
+// AddService adds a service with the default options. e.g. a duplicate name or adding after StartServices is an error
+func (c *Core[B]) AddService(s svc.Service) error {
+	return c.AddServiceWithOptions(s, svc.Options{})
 }
 
-func (c *Core[B]) StartServices() error {
-	c.done = make(chan error, len(c.services))
-	for _, s := range c.services {
-		err := s.Start()
-		if err != nil {
-			return err
-		}
-		go func(s svc.Service) {
-			err := <-s.Done()
-			c.done <- err
-		}(s) // pass the loop var to the param. otherwise, they are captured inside goroutine lazily
+// AddServiceWithOptions adds a service with dependencies, drain timeout and restart policy
+func (c *Core[B]) AddServiceWithOptions(s svc.Service, opts svc.Options) error {
+	log.Printf("[INFO] adding service: %s", s.Name())
+	if err := c.services.Add(s, opts); err != nil {
+		return err
 	}
+	log.Printf("[INFO] total services: %d", len(c.services.Services()))
 	return nil
 }
 
+// ConfigureService modifies the options of an added service before StartServices
+// e.g. c.ConfigureService("WebService", func(o *svc.Options) { o.DependsOn = []string{"JobScheduler", "ThrottleBucketStore"} })
+func (c *Core[B]) ConfigureService(name string, configure func(opts *svc.Options)) error {
+	return c.services.Configure(name, configure)
+}
+
+// Services returns the current instances of the added services
+func (c *Core[B]) Services() []svc.Service {
+	return c.services.Services()
+}
+
+// StartServices starts the services in dependency order.
+// They are stopped in reverse order when RootCtx is done.
+func (c *Core[B]) StartServices() error {
+	return c.services.Start()
+}
+
+// WaitServicesDone waits until all services are finished.
+// If a service fails without being restarted, the others are stopped and RootCtx is cancelled.
 func (c *Core[B]) WaitServicesDone() error {
-	for i := 0; i < len(c.services); i++ {
-		if err := <-c.done; err != nil {
-			return err
-		}
+	err := c.services.Wait()
+	if err != nil {
+		c.RootCancel()
 	}
-	return nil
+	return err
 }
 
+// StopServices stops the services in reverse dependency order
 func (c *Core[B]) StopServices() {
-	for _, s := range c.services {
-		s.Stop()
-	}
+	c.services.Stop()
 }
 
 var once sync.Once
@@ -142,104 +176,314 @@ func (c *Core[B]) startShutdownSignalListener() {
 			sig := <-sigs
 			log.Printf("[INFO] got signal [%s]. shutting down app [%s] ...", sig, c.AppName)
 			c.RootCancel() // broadcast to all child services via Context.Done()
+			sig = <-sigs
+			log.Printf("[WARN] got signal [%s] again. forcing shutdown of app [%s] ...", sig, c.AppName)
+			c.services.Force()
 		}()
 	})
 	log.Printf("[INFO][CORE] shutdown signal listener started")
 }
 
-func (c *Core[B]) PrepareJobScheduler() {
-	c.JobScheduler = schedjobs.NewScheduler(c.RootCtx)
-	c.AddService(c.JobScheduler)
+func (c *Core[B]) PrepareJobScheduler() error {
+	c.JobScheduler = schedjobs.NewScheduler(c.ServiceCtx)
+	return c.AddServiceWithOptions(c.JobScheduler, svc.Options{})
 }
 
-func (c *Core[B]) PrepareUDSService(cmdStore *uds.CommandStore) error {
-	confFilePath := filepath.Join(c.AppRoot, "config", ".uds.json")
-	confBytes, err := os.ReadFile(confFilePath) // ([]byte, error)
-	if err != nil {
-		return err
+// PrepareKVJobStore persists the stored one-time jobs of JobScheduler on the KV DB client kvdbName (empty = default)
+// Prerequisite: PrepareJobScheduler, PrepareKVDatabases
+func (c *Core[B]) PrepareKVJobStore(kvdbName string) error {
+	if c.JobScheduler == nil {
+		return errors.New("job scheduler not ready")
 	}
-	conf := uds.Conf{}
-	if err = json.Unmarshal(confBytes, &conf); err != nil {
-		return err
+	client, ok := c.GetKVDBClient(kvdbName)
+	if !ok {
+		return fmt.Errorf("kvdb client %q not found", kvdbName)
 	}
-	c.UDSService = uds.NewService(c.RootCtx, conf, cmdStore)
-	c.AddService(c.UDSService)
+	c.JobScheduler.Store = schedjobs.NewKVJobStore(client, c.AppName+"_jobs:onetime")
 	return nil
 }
 
-func (c *Core[B]) PrepareWebService(addr string, router http.Handler) {
-	c.WebService = web.NewService(c.RootCtx, addr, router)
-	c.AddService(c.WebService)
+// PrepareSQLJobStore persists the stored one-time jobs of JobScheduler in table of the SQL DB client sqldbName.
+// See schedjobs.SQLJobStore for the schema
+// Prerequisite: PrepareJobScheduler, PrepareSQLDatabases
+func (c *Core[B]) PrepareSQLJobStore(sqldbName string, table string) error {
+	if c.JobScheduler == nil {
+		return errors.New("job scheduler not ready")
+	}
+	client, ok := c.BackendSQLDBClients[sqldbName]
+	if !ok {
+		return fmt.Errorf("sqldb client %q not found", sqldbName)
+	}
+	store, err := schedjobs.NewSQLJobStore(client, table)
+	if err != nil {
+		return err
+	}
+	c.JobScheduler.Store = store
+	return nil
 }
 
-func (c *Core[B]) PrepareThrottleBucketStore(cleanupCycle time.Duration, cleanupOlderThan time.Duration) {
-	c.ThrottleBucketStore = throttle.NewBucketStore[B](c.RootCtx, cleanupCycle, cleanupOlderThan)
-	c.AddService(c.ThrottleBucketStore)
+// PrepareKVClusterLock makes the Singleton cron jobs of JobScheduler run on one instance
+// among those sharing the KV DB client kvdbName (empty = default)
+// Prerequisite: PrepareJobScheduler, PrepareKVDatabases
+func (c *Core[B]) PrepareKVClusterLock(kvdbName string) error {
+	if c.JobScheduler == nil {
+		return errors.New("job scheduler not ready")
+	}
+	client, ok := c.GetKVDBClient(kvdbName)
+	if !ok {
+		return fmt.Errorf("kvdb client %q not found", kvdbName)
+	}
+	c.JobScheduler.ClusterLock = schedjobs.NewKVClusterLock(client, c.AppName+"_cronrun")
+	return nil
 }
 
-func (c *Core[B]) LoadStorageConf() error {
-	confFilePath := filepath.Join(c.AppRoot, "config", ".storages.json")
-	confBytes, err := os.ReadFile(confFilePath) // ([]byte, error)
+// PreparePGClusterLock makes the Singleton cron jobs of JobScheduler run on one instance
+// among those sharing table of the PostgreSQL client sqldbName. See schedjobs.PGClusterLock for the schema
+// Prerequisite: PrepareJobScheduler, PrepareSQLDatabases
+func (c *Core[B]) PreparePGClusterLock(sqldbName string, table string) error {
+	if c.JobScheduler == nil {
+		return errors.New("job scheduler not ready")
+	}
+	client, ok := c.BackendSQLDBClients[sqldbName]
+	if !ok {
+		return fmt.Errorf("sqldb client %q not found", sqldbName)
+	}
+	lock, err := schedjobs.NewPGClusterLock(client, table)
 	if err != nil {
 		return err
 	}
-	if err = json.Unmarshal(confBytes, &c.StorageConf); err != nil {
-		return err
+	c.JobScheduler.ClusterLock = lock
+	return nil
+}
+
+// PrepareDistLocker prepares DistLocker on the KV DB client kvdbName (empty = default), namespaced by AppName
+// Prerequisite: PrepareKVDatabases
+func (c *Core[B]) PrepareDistLocker(kvdbName string) error {
+	client, ok := c.GetKVDBClient(kvdbName)
+	if !ok {
+		return fmt.Errorf("kvdb client %q not found", kvdbName)
 	}
+	c.DistLocker = kvdblocks.NewLocker(client, c.AppName+"_lock")
 	return nil
 }
 
-func (c *Core[B]) PrepareKVDatabase() error {
-	// Load KV Database Config File
-	err := c.loadKVDBConf()
-	if err != nil {
-		return err
+// PrepareQueue creates a durable work queue on the KV DB client kvdbName (empty = default)
+// and adds it as a service processing jobs with handler. Namespaced by AppName and name
+// Prerequisite: PrepareKVDatabases
+func (c *Core[B]) PrepareQueue(name string, kvdbName string, conf queue.Conf, handler queue.Handler) (*queue.Queue, error) {
+	client, ok := c.GetKVDBClient(kvdbName)
+	if !ok {
+		return nil, fmt.Errorf("kvdb client %q not found", kvdbName)
+	}
+	if _, exists := c.Queues[name]; exists {
+		return nil, fmt.Errorf("queue %s already prepared", name)
 	}
-	if err = c.prepareKVDBClient(); err != nil {
+	q := queue.New(c.ServiceCtx, client, c.AppName+"_queue:"+name, conf, handler)
+	if err := c.AddServiceWithOptions(q, svc.Options{}); err != nil {
+		return nil, err
+	}
+	if c.Queues == nil {
+		c.Queues = make(map[string]*queue.Queue)
+	}
+	c.Queues[name] = q
+	return q, nil
+}
+
+// PrepareUDSService adds the UDS service with the commands in cmdStore and the built-in ones.
+// The "jobs" commands are added if PrepareJobScheduler is done before
+func (c *Core[B]) PrepareUDSService(cmdStore *uds.CommandStore) error {
+	conf := uds.Conf{}
+	if err := c.ConfLoader.Load(".uds.json", "UDS", &conf); err != nil {
 		return err
 	}
+	if cmdStore == nil {
+		cmdStore = uds.NewCommandStore()
+	}
+	cmdStore.AddGroups(c.builtinCommandGroup())
+	if c.JobScheduler != nil {
+		cmdStore.AddGroups(c.JobScheduler.CommandGroup())
+	}
+	c.UDSService = uds.NewService(c.ServiceCtx, conf, cmdStore)
+	return c.AddServiceWithOptions(c.UDSService, svc.Options{})
+}
+
+func (c *Core[B]) PrepareWebService(addr string, router http.Handler) error {
+	c.WebService = web.NewService(c.ServiceCtx, addr, router)
+	return c.AddServiceWithOptions(c.WebService, svc.Options{})
+}
+
+func (c *Core[B]) PrepareThrottleBucketStore(cleanupCycle time.Duration, cleanupOlderThan time.Duration) error {
+	c.ThrottleBucketStore = throttle.NewBucketStore[B](c.ServiceCtx, cleanupCycle, cleanupOlderThan)
+	return c.AddServiceWithOptions(c.ThrottleBucketStore, svc.Options{})
+}
+
+// PrepareKVThrottle shares the buckets of ThrottleBucketStore across the instances using the KV DB client kvdbName (empty = default)
+// The local buckets are used while the KV DB is unavailable
+// Prerequisite: PrepareThrottleBucketStore, PrepareKVDatabases
+func (c *Core[B]) PrepareKVThrottle(kvdbName string) error {
+	if c.ThrottleBucketStore == nil {
+		return errors.New("throttle bucket store not ready")
+	}
+	client, ok := c.GetKVDBClient(kvdbName)
+	if !ok {
+		return fmt.Errorf("kvdb client %q not found", kvdbName)
+	}
+	c.ThrottleBucketStore.KV = throttle.NewKVBackend(client, c.AppName+"_throttle")
 	return nil
 }
 
-func (c *Core[B]) loadKVDBConf() error {
-	confFilePath := filepath.Join(c.AppRoot, "config", ".kv-databases.json")
-	confBytes, err := os.ReadFile(confFilePath) // ([]byte, error)
+// LoadThrottleBucketConfs loads config/.throttle.json {groupID: throttle.BucketConf} into ThrottleBucketStore
+// Groups set in code by ThrottleBucketStore.SetBucketGroup are kept on Reload unless the file has the same groupID
+// Prerequisite: ThrottleBucketStore
+func (c *Core[B]) LoadThrottleBucketConfs() error {
+	if c.ThrottleBucketStore == nil {
+		return errors.New("throttle bucket store not ready")
+	}
+	confs, err := c.newThrottleBucketConfs()
 	if err != nil {
 		return err
 	}
-	if err = json.Unmarshal(confBytes, &c.KVDBConf); err != nil {
+	c.applyThrottleBucketConfs(confs)
+	c.addReloader("ThrottleBucketConfs", func() (func(), error) {
+		confs, err := c.newThrottleBucketConfs()
+		if err != nil {
+			return nil, err
+		}
+		return func() { c.applyThrottleBucketConfs(confs) }, nil
+	})
+	return nil
+}
+
+func (c *Core[B]) newThrottleBucketConfs() (map[string]*throttle.BucketConf, error) {
+	var confs map[string]*throttle.BucketConf
+	if err := c.ConfLoader.Load(".throttle.json", "THROTTLE", &confs); err != nil {
+		return nil, err
+	}
+	for groupID, conf := range confs {
+		if conf == nil {
+			return nil, fmt.Errorf("throttle bucket group %q: empty conf", groupID)
+		}
+		if err := conf.Validate(); err != nil {
+			return nil, fmt.Errorf("throttle bucket group %q: %w", groupID, err)
+		}
+	}
+	return confs, nil
+}
+
+func (c *Core[B]) applyThrottleBucketConfs(confs map[string]*throttle.BucketConf) {
+	for groupID, conf := range confs {
+		c.ThrottleBucketStore.SetBucketGroup(groupID, conf)
+	}
+}
+
+// RateLimiter returns a routing.HandlerWrapper limiting the requests by the BucketGroups of ThrottleBucketStore
+// e.g. limiter, err := c.RateLimiter(throttle.Limit[string]{Group: "login_ip", Key: throttle.KeyByClientIP})
+// then router.Handle("POST /login", h, limiter)
+// The built-in key extractors key by string. For B other than string, adapt them by throttle.KeyAs.
+// e.g. throttle.Limit[int64]{Group: "login_ip", Key: throttle.KeyAs(throttle.KeyByClientIP, throttle.HashKey)}
+// Prerequisite: ThrottleBucketStore with the BucketGroups of the limits set. e.g. LoadThrottleBucketConfs
+func (c *Core[B]) RateLimiter(limits ...throttle.Limit[B]) (*throttle.Limiter[B], error) {
+	if c.ThrottleBucketStore == nil {
+		return nil, errors.New("throttle bucket store not ready")
+	}
+	return throttle.NewLimiter(c.ThrottleBucketStore, limits...)
+}
+
+func (c *Core[B]) LoadStorageConf() error {
+	return c.ConfLoader.Load(".storages.json", "STORAGES", &c.StorageConf)
+}
+
+// PrepareKVDatabases for Key-value DB Clients
+// config/.kv-databases.json is a map of named confs. e.g. {"default": {...}, "cache": {...}}
+// With more than one, one of them must be named "default"
+// A single conf {"type": "redis", ...} is also accepted as the default one
+func (c *Core[B]) PrepareKVDatabases() error {
+	if err := c.loadKVDBConfs(); err != nil {
 		return err
 	}
-	return nil
+	return c.prepareKVDBClients()
 }
 
-func (c *Core[B]) prepareKVDBClient() error {
-	switch c.KVDBConf.Type {
-	case "redis":
-		c.BackendKVDBClient = &redis.Client{Conf: &c.KVDBConf}
-		if err := c.BackendKVDBClient.Init(); err != nil {
+// PrepareKVDatabase
+// Deprecated: use PrepareKVDatabases
+func (c *Core[B]) PrepareKVDatabase() error {
+	return c.PrepareKVDatabases()
+}
+
+func (c *Core[B]) loadKVDBConfs() error {
+	var raw map[string]jsontext.Value
+	if err := c.ConfLoader.Load(".kv-databases.json", "KVDB", &raw); err != nil {
+		return err
+	}
+	c.KVDBConfs = make(map[string]*kvdb.Conf, len(raw))
+	// the single form has "type" as a string. a named client "type" is an object
+	if typeVal, ok := raw["type"]; ok && typeVal.Kind() == '"' {
+		conf := &kvdb.Conf{}
+		if err := c.ConfLoader.Load(".kv-databases.json", "KVDB", conf); err != nil {
 			return err
 		}
-	// case "memcached"
-	default:
-		return errors.New("unsupported key-value database type")
+		c.KVDBConfs[DefaultKVDBName] = conf
+		return nil
+	}
+	for name, val := range raw {
+		conf := &kvdb.Conf{}
+		if err := json.Unmarshal(val, conf); err != nil {
+			return fmt.Errorf("kv database %q: %w", name, err)
+		}
+		c.KVDBConfs[name] = conf
+	}
+	if _, ok := c.KVDBConfs[DefaultKVDBName]; !ok && len(c.KVDBConfs) > 1 {
+		return fmt.Errorf("kv databases: %d clients are named but none is %q", len(c.KVDBConfs), DefaultKVDBName)
 	}
 	return nil
 }
 
-func (c *Core[B]) loadSQLDBConfs() error {
-	confFilePath := filepath.Join(c.AppRoot, "config", ".sql-databases.json")
-	confBytes, err := os.ReadFile(confFilePath) // ([]byte, error)
-	if err != nil {
-		return err
+// prepareKVDBClients - Build & Init KV DB Clients
+// Use after loadKVDBConfs
+func (c *Core[B]) prepareKVDBClients() error {
+	c.BackendKVDBClients = make(map[string]kvdb.Client)
+
+	// Registering Supported Implementations
+	redis.Register()
+	memory.Register()
+
+	// Prepare New Clients
+	for name, kvDBConf := range c.KVDBConfs {
+		kvClient, err := kvdb.New(kvDBConf.Type, kvDBConf)
+		if err != nil {
+			return fmt.Errorf("kv database %q: %w", name, err)
+		}
+		if err = kvClient.Init(); err != nil {
+			return fmt.Errorf("kv database %q: %w", name, err)
+		}
+		c.BackendKVDBClients[name] = kvClient
 	}
-	c.SQLDBConfs = make(map[string]*sqldb.Conf)
-	if err = json.Unmarshal(confBytes, &c.SQLDBConfs); err != nil {
-		return err
+
+	// Default Client
+	if kvClient, ok := c.BackendKVDBClients[DefaultKVDBName]; ok {
+		c.BackendKVDBClient = kvClient
+	} else if len(c.BackendKVDBClients) == 1 {
+		for _, kvClient := range c.BackendKVDBClients {
+			c.BackendKVDBClient = kvClient
+		}
 	}
 	return nil
 }
 
+// GetKVDBClient returns a KV DB Client by name. empty name = BackendKVDBClient (default)
+func (c *Core[B]) GetKVDBClient(name string) (kvdb.Client, bool) {
+	if name == "" {
+		return c.BackendKVDBClient, c.BackendKVDBClient != nil
+	}
+	kvClient, ok := c.BackendKVDBClients[name]
+	return kvClient, ok
+}
+
+func (c *Core[B]) loadSQLDBConfs() error {
+	c.SQLDBConfs = make(map[string]*sqldb.Conf)
+	return c.ConfLoader.Load(".sql-databases.json", "SQLDB", &c.SQLDBConfs)
+}
+
 // prepareSQLDBClients - Build & Init SQL DB Clients
 // Use after loadSQLDBConfs
 func (c *Core[B]) prepareSQLDBClients() error {
@@ -314,17 +558,19 @@ func (c *Core[B]) PrepareClientApps() error {
 		return err
 	}
 	c.ClientApps.Store(&newClientApps) // atomic store
+	c.addReloader("ClientApps", func() (func(), error) {
+		newClientApps, err := c.newClientAppsConfMapFromFile()
+		if err != nil {
+			return nil, err
+		}
+		return func() { c.ClientApps.Store(&newClientApps) }, nil
+	})
 	return nil
 }
 
 func (c *Core[B]) newClientAppsConfMapFromFile() (map[string]clients.ClientAppConf, error) {
-	confFilePath := filepath.Join(c.AppRoot, "config", ".clients.json")
-	confBytes, err := os.ReadFile(confFilePath) // ([]byte, error)
-	if err != nil {
-		return nil, err
-	}
 	var confMap map[string]clients.ClientAppConf
-	if err = json.Unmarshal(confBytes, &confMap); err != nil {
+	if err := c.ConfLoader.Load(".clients.json", "CLIENTS", &confMap); err != nil {
 		return nil, err
 	}
 	return confMap, nil
@@ -343,73 +589,145 @@ func (c *Core[B]) GetClientAppConf(id string) (clients.ClientAppConf, bool) {
 }
 
 // PrepareWebSessions prepares WebSessionManager
-// Prerequisite: BackendKVDBClient
+// Prerequisite: BackendKVDBClients. "kvdb" in .web-session.json selects one. empty = default
 // Prerequisite: SessionLocks
 func (c *Core[B]) PrepareWebSessions() error {
-	confFilePath := filepath.Join(c.AppRoot, "config", ".web-session.json")
-	confBytes, err := os.ReadFile(confFilePath) // ([]byte, error)
+	mgr, err := c.newWebSessionManager()
 	if err != nil {
 		return err
 	}
-	if c.BackendKVDBClient == nil {
-		return errors.New("backend KVDB client not ready")
-	}
+	c.webSessionManager.Store(mgr)
+	c.WebSessionManager = mgr
+	c.addReloader("WebSessions", func() (func(), error) {
+		mgr, err := c.newWebSessionManager()
+		if err != nil {
+			return nil, err
+		}
+		return func() { c.webSessionManager.Store(mgr) }, nil
+	})
+	return nil
+}
+
+func (c *Core[B]) newWebSessionManager() (*session.Manager, error) {
 	if c.SessionLocks == nil {
-		return errors.New("sessionlocks not ready")
+		return nil, errors.New("sessionlocks not ready")
 	}
 	mgr := &session.Manager{
-		AppName:           c.AppName,
-		BackendKVDBClient: c.BackendKVDBClient,
-		SessionLocks:      c.SessionLocks,
+		AppName:      c.AppName,
+		SessionLocks: c.SessionLocks,
 	}
-	if err = json.Unmarshal(confBytes, &mgr.Conf); err != nil {
-		return err
+	if err := c.ConfLoader.Load(".web-session.json", "WEB_SESSION", &mgr.Conf); err != nil {
+		return nil, err
 	}
+	kvClient, ok := c.GetKVDBClient(mgr.Conf.KVDBName)
+	if !ok {
+		return nil, fmt.Errorf("backend KVDB client %q not ready", mgr.Conf.KVDBName)
+	}
+	mgr.BackendKVDBClient = kvClient
 	// Web Login Session Cipher
 	cipher, err := security.NewXChaCha20Poly1305CipherBase64([]byte(mgr.Conf.EncryptionKey))
 	if err != nil {
-		return fmt.Errorf("NewXChaCha20Poly1305Cipher: %v", err)
+		return nil, fmt.Errorf("NewXChaCha20Poly1305Cipher: %v", err)
 	}
 	mgr.Cipher = cipher
+	return mgr, nil
+}
 
-	c.WebSessionManager = mgr
-	return nil
+// GetWebSessionManager reads the current WebSessionManager
+// Do not keep the returned pointer across requests. It can be swapped by Reload
+func (c *Core[B]) GetWebSessionManager() *session.Manager {
+	return c.webSessionManager.Load()
 }
 
 // PrepareMainBackendClient to Send Request to the Main Backend API if any
 // Prerequisite: BackendHttpClient
 func (c *Core[B]) PrepareMainBackendClient() error {
-	confFilePath := filepath.Join(c.AppRoot, "config", ".main-backend-api.json")
-	confBytes, err := os.ReadFile(confFilePath) // ([]byte, error)
+	client, err := c.newMainBackendClient()
 	if err != nil {
 		return err
 	}
+	c.mainBackendClient.Store(client)
+	c.MainBackendClient = client
+	c.addReloader("MainBackendClient", func() (func(), error) {
+		client, err := c.newMainBackendClient()
+		if err != nil {
+			return nil, err
+		}
+		return func() { c.mainBackendClient.Store(client) }, nil
+	})
+	return nil
+}
+
+func (c *Core[B]) newMainBackendClient() (*mainbackend.Client, error) {
 	if c.BackendHttpClient == nil {
-		return errors.New("backend http client not ready")
+		return nil, errors.New("backend http client not ready")
 	}
-	c.MainBackendClient = &mainbackend.Client{
+	client := &mainbackend.Client{
 		Client: c.BackendHttpClient,
 	}
-	if err = json.Unmarshal(confBytes, &c.MainBackendClient.Conf); err != nil {
+	if err := c.ConfLoader.Load(".main-backend-api.json", "MAIN_BACKEND", &client.Conf); err != nil {
+		return nil, err
+	}
+	if client.Conf == nil || client.Conf.Host == "" {
+		return nil, errors.New("main backend host not set")
+	}
+	return client, nil
+}
+
+// GetMainBackendClient reads the current MainBackendClient
+func (c *Core[B]) GetMainBackendClient() *mainbackend.Client {
+	return c.mainBackendClient.Load()
+}
+
+// DumpConfig writes all configs loaded through ConfLoader with secrets redacted
+func (c *Core[B]) DumpConfig(w io.Writer) error {
+	if c.ConfLoader == nil {
+		return errors.New("config loader not ready")
+	}
+	return c.ConfLoader.Dump(w)
+}
+
+func (c *Core[B]) PrepareHTMLTemplateStore() error {
+	store, err := c.newHTMLTemplateStore()
+	if err != nil {
 		return err
 	}
+	c.htmlTemplateStore.Store(store)
+	c.HTMLTemplateStore = store
+	c.addReloader("HTMLTemplateStore", func() (func(), error) {
+		store, err := c.newHTMLTemplateStore()
+		if err != nil {
+			return nil, err
+		}
+		return func() { c.htmlTemplateStore.Store(store) }, nil
+	})
 	return nil
 }
 
-func (c *Core[B]) PrepareHTMLTemplateStore() error {
-	c.HTMLTemplateStore = tpl.NewHTMLTemplateStore()
-	return c.HTMLTemplateStore.LoadBaseTemplates(
-		filepath.Join(c.AppRoot, "templates", "html"),
-	)
+func (c *Core[B]) newHTMLTemplateStore() (*tpl.HTMLTemplateStore, error) {
+	store := tpl.NewHTMLTemplateStore()
+	if err := store.LoadBaseTemplates(filepath.Join(c.AppRoot, "templates", "html")); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// GetHTMLTemplateStore reads the current HTMLTemplateStore
+func (c *Core[B]) GetHTMLTemplateStore() *tpl.HTMLTemplateStore {
+	return c.htmlTemplateStore.Load()
 }
 
 func (c *Core[B]) ResourceCleanUp() {
 	log.Println("[INFO] App Resource Cleaning Up...")
 	// Clean up DB clients ----
 	// ToDo: factor out this
-	if c.BackendKVDBClient != nil {
-		if err := c.BackendKVDBClient.Close(); err != nil {
-			log.Println("[ERROR] Failed to close KV database client")
+	for name, kvDBClient := range c.BackendKVDBClients {
+		dbType := kvDBClient.GetConf().Type
+		log.Printf("[INFO][%s] Closing %q KV DB client", dbType, name)
+		if err := kvDBClient.Close(); err != nil {
+			log.Printf("[ERROR][%s] Failed to close %q KV DB client", dbType, name)
+		} else {
+			log.Printf("[INFO][%s] %q KV DB client closed", dbType, name)
 		}
 	}
 	for name, sqlDBClient := range c.BackendSQLDBClients {
@@ -0,0 +1,81 @@
+package framework
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeptools/gw-core/health"
+	"github.com/zeptools/gw-core/svc"
+)
+
+// PrepareHealthChecks prepares HealthRegistry with the default checks
+//   - liveness:  each added service not stalled. i.e. stopped outside shutdown and restart backoff
+//   - readiness: RootCtx not cancelled, DebugOpts.MaintenanceMode off,
+//     state of each added service, Ping of each BackendSQLDBClients and BackendKVDBClients entry
+//
+// If drainDelay > 0, WebService waits drainDelay before shutting down after RootCtx is cancelled,
+// so that load balancers observe the failing readiness and drain the traffic first.
+// Call after the Prepare* methods of the backends and services to check
+// Mount the handlers e.g.
+//
+//	router.HandleFunc("GET /healthz", c.HealthRegistry.ServeLiveness)
+//	router.HandleFunc("GET /readyz", c.HealthRegistry.ServeReadiness)
+func (c *Core[B]) PrepareHealthChecks(drainDelay time.Duration) error {
+	reg := health.NewRegistry()
+
+	reg.AddReadinessCheck("shutdown", func(_ context.Context) error {
+		if c.RootCtx.Err() != nil {
+			return errors.New("shutting down")
+		}
+		return nil
+	})
+	reg.AddReadinessCheck("maintenance", func(_ context.Context) error {
+		if c.DebugOpts.MaintenanceMode != 0 {
+			return errors.New("maintenance mode")
+		}
+		return nil
+	})
+	for _, s := range c.Services() {
+		name := s.Name()
+		reg.AddReadinessCheck("service:"+name, func(_ context.Context) error {
+			cur, ok := c.services.Get(name) // can be swapped by restart
+			if !ok {
+				return errors.New("not found")
+			}
+			if state := cur.State(); state != svc.StateRUNNING {
+				return fmt.Errorf("state: %s", svc.StateName(state))
+			}
+			return nil
+		})
+		reg.AddLivenessCheck("service:"+name, func(_ context.Context) error {
+			// not running while draining, shutting down or restarting is expected. only a wedged process fails
+			if c.RootCtx.Err() != nil {
+				return nil
+			}
+			stalled, err := c.services.Stalled(name)
+			if err != nil {
+				return err
+			}
+			if stalled {
+				return errors.New("stopped")
+			}
+			return nil
+		})
+	}
+	for dbName, client := range c.BackendSQLDBClients {
+		reg.AddReadinessCheck("sqldb:"+dbName, client.Ping)
+	}
+	for dbName, client := range c.BackendKVDBClients {
+		reg.AddReadinessCheck("kvdb:"+dbName, client.Ping)
+	}
+	c.HealthRegistry = reg
+
+	if drainDelay > 0 && c.WebService != nil {
+		return c.ConfigureService(c.WebService.Name(), func(opts *svc.Options) {
+			opts.StopDelay = drainDelay
+		})
+	}
+	return nil
+}
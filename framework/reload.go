@@ -0,0 +1,87 @@
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// reloader prepares a new state of a hot-reloadable part without applying it.
+// prepare returns a commit func that swaps the new state in, which must not fail.
+type reloader struct {
+	name    string
+	prepare func() (commit func(), err error)
+}
+
+// addReloader registers a hot reload target. Registering the same name again is ignored,
+// so Prepare* methods can be invoked more than once.
+func (c *Core[B]) addReloader(name string, prepare func() (func(), error)) {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	for _, r := range c.reloaders {
+		if r.name == name {
+			return
+		}
+	}
+	c.reloaders = append(c.reloaders, reloader{name: name, prepare: prepare})
+}
+
+// Reload re-reads all hot-reloadable configs prepared so far
+// (ClientApps, WebSessions, MainBackendClient, ThrottleBucketConfs, HTMLTemplateStore).
+// All of them are loaded and validated first, then swapped together.
+// If any of them fails, nothing is swapped and the joined errors are returned.
+func (c *Core[B]) Reload() error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	log.Printf("[INFO][RELOAD] reloading %d targets ...", len(c.reloaders))
+	c.ConfLoader.Stage() // Dump shows the reloaded configs only when they are swapped in
+	commits := make([]func(), 0, len(c.reloaders))
+	var errs []error
+	for _, r := range c.reloaders {
+		commit, err := r.prepare()
+		if err != nil {
+			log.Printf("[ERROR][RELOAD] %s: %v", r.name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", r.name, err))
+			continue
+		}
+		commits = append(commits, commit)
+	}
+	if len(errs) > 0 {
+		c.ConfLoader.Discard()
+		log.Println("[ERROR][RELOAD] rolled back. current configs are kept")
+		return errors.Join(errs...)
+	}
+	for _, commit := range commits {
+		commit()
+	}
+	c.ConfLoader.Commit()
+	log.Println("[INFO][RELOAD] reload complete")
+	return nil
+}
+
+var reloadOnce sync.Once
+
+// startReloadSignalListener reloads on SIGHUP
+func (c *Core[B]) startReloadSignalListener() {
+	reloadOnce.Do(func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGHUP)
+		go func() {
+			for {
+				select {
+				case <-c.RootCtx.Done():
+					signal.Stop(sigs)
+					return
+				case sig := <-sigs:
+					log.Printf("[INFO] got signal [%s]. reloading app [%s] ...", sig, c.AppName)
+					_ = c.Reload() // errors are logged
+				}
+			}
+		}()
+	})
+	log.Printf("[INFO][CORE] reload signal listener started")
+}
@@ -0,0 +1,65 @@
+package framework
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zeptools/gw-core/uds"
+)
+
+// Built-in UDS Commands. Added to the "core" group by PrepareUDSService
+
+type reloadCommand[B comparable] struct {
+	core *Core[B]
+}
+
+func (h *reloadCommand[B]) Command() string {
+	return "reload"
+}
+
+func (h *reloadCommand[B]) Desc() string {
+	return "hot reload configs (same as SIGHUP)"
+}
+
+func (h *reloadCommand[B]) Usage() string {
+	return "reload"
+}
+
+func (h *reloadCommand[B]) HandleCommand(_ []string, w io.Writer) error {
+	if err := h.core.Reload(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w, "reloaded")
+	return nil
+}
+
+type configDumpCommand[B comparable] struct {
+	core *Core[B]
+}
+
+func (h *configDumpCommand[B]) Command() string {
+	return "config-dump"
+}
+
+func (h *configDumpCommand[B]) Desc() string {
+	return "print loaded configs with secrets redacted"
+}
+
+func (h *configDumpCommand[B]) Usage() string {
+	return "config-dump"
+}
+
+func (h *configDumpCommand[B]) HandleCommand(_ []string, w io.Writer) error {
+	if err := h.core.DumpConfig(w); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w)
+	return nil
+}
+
+func (c *Core[B]) builtinCommandGroup() *uds.CommandGroup {
+	return uds.NewCommandGroup("core",
+		&reloadCommand[B]{core: c},
+		&configDumpCommand[B]{core: c},
+	)
+}
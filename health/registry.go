@@ -0,0 +1,141 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zeptools/gw-core/responses"
+)
+
+const DefaultCheckTimeout = 2 * time.Second
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check returns nil when healthy
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Registry holds liveness and readiness checks and serves them as JSON
+type Registry struct {
+	CheckTimeout time.Duration // per-check timeout. 0 = DefaultCheckTimeout
+
+	mu        sync.RWMutex
+	liveness  []namedCheck
+	readiness []namedCheck
+}
+
+func NewRegistry() *Registry {
+	return &Registry{CheckTimeout: DefaultCheckTimeout}
+}
+
+// AddLivenessCheck adds a check to /healthz. A failing liveness means the process should be restarted
+func (r *Registry) AddLivenessCheck(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness = append(r.liveness, namedCheck{name: name, check: check})
+}
+
+// AddReadinessCheck adds a check to /readyz. A failing readiness means no traffic should be routed
+func (r *Registry) AddReadinessCheck(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness = append(r.readiness, namedCheck{name: name, check: check})
+}
+
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Liveness runs all liveness checks concurrently
+func (r *Registry) Liveness(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]namedCheck(nil), r.liveness...)
+	r.mu.RUnlock()
+	return r.run(ctx, checks)
+}
+
+// Readiness runs all readiness checks concurrently
+func (r *Registry) Readiness(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]namedCheck(nil), r.readiness...)
+	r.mu.RUnlock()
+	return r.run(ctx, checks)
+}
+
+// ServeLiveness is an http handler func for /healthz
+// e.g. router.HandleFunc("GET /healthz", registry.ServeLiveness)
+func (r *Registry) ServeLiveness(w http.ResponseWriter, req *http.Request) {
+	writeReport(w, r.Liveness(req.Context()))
+}
+
+// ServeReadiness is an http handler func for /readyz
+// e.g. router.HandleFunc("GET /readyz", registry.ServeReadiness)
+func (r *Registry) ServeReadiness(w http.ResponseWriter, req *http.Request) {
+	writeReport(w, r.Readiness(req.Context()))
+}
+
+func (r *Registry) run(ctx context.Context, checks []namedCheck) Report {
+	timeout := r.CheckTimeout
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, nc := range checks {
+		wg.Go(func() {
+			result := runCheck(ctx, nc.check, timeout)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[nc.name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		})
+	}
+	wg.Wait()
+	return report
+}
+
+func runCheck(ctx context.Context, check Check, timeout time.Duration) (result CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			result = CheckResult{Status: StatusFail, Error: "panic in health check"}
+		}
+		result.DurationMS = time.Since(start).Milliseconds()
+	}()
+	if err := check(ctx); err != nil {
+		return CheckResult{Status: StatusFail, Error: err.Error()}
+	}
+	return CheckResult{Status: StatusOK}
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Cache-Control", "no-store")
+	statusCode := http.StatusOK
+	if report.Status != StatusOK {
+		statusCode = http.StatusServiceUnavailable
+	}
+	responses.EncodeWriteJSON(w, statusCode, report)
+}
@@ -0,0 +1,90 @@
+package keymutex
+
+import (
+	"context"
+	"sync"
+)
+
+// Mutex is a set of mutexes by key, e.g. one per user.
+// An entry exists only while the key is held or waited for, so the keys do not accumulate
+type Mutex struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	refs int           // holder and waiters
+	sem  chan struct{} // buffer 1. full = locked
+}
+
+func New() *Mutex {
+	return &Mutex{entries: make(map[string]*entry)}
+}
+
+// Lock waits until the key is locked or ctx is done
+func (m *Mutex) Lock(ctx context.Context, key string) error {
+	e := m.ref(key)
+	select {
+	case e.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		m.unref(key, e)
+		return ctx.Err()
+	}
+}
+
+// TryLock locks the key without waiting. Returns false if it is held
+func (m *Mutex) TryLock(key string) bool {
+	e := m.ref(key)
+	select {
+	case e.sem <- struct{}{}:
+		return true
+	default:
+		m.unref(key, e)
+		return false
+	}
+}
+
+// Unlock unlocks the key. Like sync.Mutex, it is a run-time error if the key is not locked
+func (m *Mutex) Unlock(key string) {
+	m.mu.Lock()
+	e, ok := m.entries[key]
+	m.mu.Unlock()
+	if !ok {
+		panic("keymutex: unlock of unlocked key " + key)
+	}
+	select {
+	case <-e.sem:
+	default:
+		panic("keymutex: unlock of unlocked key " + key)
+	}
+	m.unref(key, e)
+}
+
+// Len returns the number of the keys held or waited for
+func (m *Mutex) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+func (m *Mutex) ref(key string) *entry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		e = &entry{sem: make(chan struct{}, 1)}
+		m.entries[key] = e
+	}
+	e.refs++
+	return e
+}
+
+func (m *Mutex) unref(key string, e *entry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e.refs--
+	if e.refs == 0 {
+		delete(m.entries, key)
+	}
+}
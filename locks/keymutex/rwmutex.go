@@ -0,0 +1,155 @@
+package keymutex
+
+import (
+	"context"
+	"sync"
+)
+
+// RWMutex is a set of reader/writer mutexes by key.
+// A waiting writer blocks new readers so that writers are not starved.
+// An entry exists only while the key is held or waited for
+type RWMutex struct {
+	mu      sync.Mutex
+	entries map[string]*rwEntry
+}
+
+type rwEntry struct {
+	refs           int // holders and waiters
+	readers        int
+	writer         bool
+	writersWaiting int
+	changed        chan struct{} // closed and replaced when the state changes
+}
+
+func NewRW() *RWMutex {
+	return &RWMutex{entries: make(map[string]*rwEntry)}
+}
+
+// Lock waits until the key is locked for writing or ctx is done
+func (m *RWMutex) Lock(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.refLocked(key)
+	e.writersWaiting++
+	for e.writer || e.readers > 0 {
+		if err := m.waitLocked(ctx, e); err != nil {
+			e.writersWaiting--
+			e.notify() // readers blocked by this writer
+			m.unrefLocked(key, e)
+			return err
+		}
+	}
+	e.writersWaiting--
+	e.writer = true
+	return nil
+}
+
+// TryLock locks the key for writing without waiting. Returns false if it is held
+func (m *RWMutex) TryLock(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok && (e.writer || e.readers > 0) {
+		return false
+	}
+	m.refLocked(key).writer = true
+	return true
+}
+
+// Unlock unlocks the key locked for writing
+func (m *RWMutex) Unlock(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || !e.writer {
+		panic("keymutex: unlock of unlocked key " + key)
+	}
+	e.writer = false
+	e.notify()
+	m.unrefLocked(key, e)
+}
+
+// RLock waits until the key is locked for reading or ctx is done
+func (m *RWMutex) RLock(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.refLocked(key)
+	for e.writer || e.writersWaiting > 0 {
+		if err := m.waitLocked(ctx, e); err != nil {
+			m.unrefLocked(key, e)
+			return err
+		}
+	}
+	e.readers++
+	return nil
+}
+
+// TryRLock locks the key for reading without waiting. Returns false if it is locked or waited for writing
+func (m *RWMutex) TryRLock(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok && (e.writer || e.writersWaiting > 0) {
+		return false
+	}
+	m.refLocked(key).readers++
+	return true
+}
+
+// RUnlock unlocks the key locked for reading
+func (m *RWMutex) RUnlock(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || e.readers == 0 {
+		panic("keymutex: runlock of unlocked key " + key)
+	}
+	e.readers--
+	if e.readers == 0 {
+		e.notify()
+	}
+	m.unrefLocked(key, e)
+}
+
+// Len returns the number of the keys held or waited for
+func (m *RWMutex) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// waitLocked waits for a change of e releasing m.mu meanwhile
+// Caller must hold m.mu
+func (m *RWMutex) waitLocked(ctx context.Context, e *rwEntry) error {
+	changed := e.changed
+	m.mu.Unlock()
+	defer m.mu.Lock()
+	select {
+	case <-changed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Caller must hold m.mu
+func (m *RWMutex) refLocked(key string) *rwEntry {
+	e, ok := m.entries[key]
+	if !ok {
+		e = &rwEntry{changed: make(chan struct{})}
+		m.entries[key] = e
+	}
+	e.refs++
+	return e
+}
+
+// Caller must hold m.mu
+func (m *RWMutex) unrefLocked(key string, e *rwEntry) {
+	e.refs--
+	if e.refs == 0 {
+		delete(m.entries, key)
+	}
+}
+
+func (e *rwEntry) notify() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
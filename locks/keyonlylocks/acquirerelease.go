@@ -2,6 +2,9 @@ package keyonlylocks
 
 import "sync"
 
+// AcquireLocks stores all the keys in lockStore, or none of them if any is already stored.
+//
+// Deprecated: the locks never expire and can be released by anyone. Use Manager.TryAcquire
 func AcquireLocks(lockStore *sync.Map, keys []string) ([]string, bool) {
 	//sort.Strings(keys) // optional, prevents deadlocks if WAIT mode added
 	var acquired []string
@@ -21,6 +24,8 @@ func AcquireLocks(lockStore *sync.Map, keys []string) ([]string, bool) {
 
 // ReleaseLocks delete locks from the lockStore *sync.Map
 // Wrap this in deferred calls to guarantee to be called even if panic occurs.
+//
+// Deprecated: use Lock.Release of Manager
 func ReleaseLocks(lockStore *sync.Map, keys []string) {
 	for _, key := range keys {
 		lockStore.Delete(key)
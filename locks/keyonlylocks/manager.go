@@ -0,0 +1,238 @@
+package keyonlylocks
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotOwner is returned when releasing or refreshing keys not held by the owner, e.g. expired and taken by another
+var ErrNotOwner = errors.New("keyonlylocks: not the owner")
+
+// Manager holds key-only locks in the process.
+//   - all the keys of an acquisition are taken at once or not at all, so acquisitions never deadlock
+//   - Acquire waits for the keys until the context is done
+//   - a lock with a TTL expires by itself, so that a missing release does not wedge the keys forever
+//   - only the owner (token) can release or refresh its keys
+type Manager struct {
+	mu      sync.Mutex
+	held    map[string]*holder
+	changed chan struct{} // closed and replaced when any key is released
+}
+
+type holder struct {
+	owner      string
+	acquiredAt time.Time
+	expireAt   time.Time // zero = no expiration
+}
+
+func (h *holder) expired(now time.Time) bool {
+	return !h.expireAt.IsZero() && !now.Before(h.expireAt)
+}
+
+// HeldLock is a snapshot of a held key for introspection
+type HeldLock struct {
+	Key        string    `json:"key"`
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpireAt   time.Time `json:"expire_at,omitzero"` // zero = no expiration
+}
+
+// Lock is a set of keys acquired by an owner
+type Lock struct {
+	m     *Manager
+	keys  []string // sorted, unique
+	owner string
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		held:    make(map[string]*holder),
+		changed: make(chan struct{}),
+	}
+}
+
+// Keys returns the locked keys in sorted order
+func (lk *Lock) Keys() []string {
+	return slices.Clone(lk.keys)
+}
+
+// Owner returns the owner token. With it, the keys can be released by Manager.Release elsewhere
+func (lk *Lock) Owner() string {
+	return lk.owner
+}
+
+// Release releases the keys. ErrNotOwner if any of them had expired and been taken by another; the others are released anyway
+func (lk *Lock) Release() error {
+	return lk.m.Release(lk.owner, lk.keys...)
+}
+
+// Refresh resets the expiration of the keys to ttl from now. 0 = no expiration
+func (lk *Lock) Refresh(ttl time.Duration) error {
+	return lk.m.refresh(lk.owner, lk.keys, ttl)
+}
+
+// TryAcquire acquires all the keys for ttl (0 = no expiration) without waiting.
+// Returns false if any of them is held by another
+func (m *Manager) TryAcquire(ttl time.Duration, keys ...string) (*Lock, bool) {
+	keys = sortedKeys(keys)
+	owner := rand.Text()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, free := m.tryLocked(keys, owner, ttl, time.Now()); !free {
+		return nil, false
+	}
+	return &Lock{m: m, keys: keys, owner: owner}, true
+}
+
+// Acquire waits until all the keys are acquired at once for ttl (0 = no expiration), or ctx is done
+func (m *Manager) Acquire(ctx context.Context, ttl time.Duration, keys ...string) (*Lock, error) {
+	keys = sortedKeys(keys)
+	owner := rand.Text()
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		m.mu.Lock()
+		nextExpiry, free := m.tryLocked(keys, owner, ttl, time.Now())
+		changed := m.changed
+		m.mu.Unlock()
+		if free {
+			return &Lock{m: m, keys: keys, owner: owner}, nil
+		}
+		var expiryC <-chan time.Time
+		if !nextExpiry.IsZero() { // wake up when a blocking lock expires
+			if timer == nil {
+				timer = time.NewTimer(time.Until(nextExpiry))
+			} else {
+				timer.Reset(time.Until(nextExpiry))
+			}
+			expiryC = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		case <-expiryC:
+		}
+	}
+}
+
+// Release releases the keys held by owner. ErrNotOwner if any of them is not held by owner
+func (m *Manager) Release(owner string, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var err error
+	released := false
+	for _, key := range keys {
+		h, ok := m.held[key]
+		if !ok || h.owner != owner || h.expired(now) {
+			err = ErrNotOwner
+			continue
+		}
+		delete(m.held, key)
+		released = true
+	}
+	if released {
+		m.notifyLocked()
+	}
+	return err
+}
+
+// Held returns the currently held keys sorted by key. Expired ones are removed
+func (m *Manager) Held() []HeldLock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	locks := make([]HeldLock, 0, len(m.held))
+	for key, h := range m.held {
+		if h.expired(now) {
+			delete(m.held, key)
+			continue
+		}
+		locks = append(locks, HeldLock{Key: key, Owner: h.owner, AcquiredAt: h.acquiredAt, ExpireAt: h.expireAt})
+	}
+	slices.SortFunc(locks, func(a, b HeldLock) int { return strings.Compare(a.Key, b.Key) })
+	return locks
+}
+
+// IsHeld reports whether key is held by anyone
+func (m *Manager) IsHeld(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.held[key]
+	return ok && !h.expired(time.Now())
+}
+
+func (m *Manager) refresh(owner string, keys []string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for _, key := range keys {
+		if h, ok := m.held[key]; !ok || h.owner != owner || h.expired(now) {
+			return ErrNotOwner
+		}
+	}
+	for _, key := range keys {
+		m.held[key].expireAt = expireAt(now, ttl)
+	}
+	return nil
+}
+
+// tryLocked takes all the keys if none of them is held by a live holder.
+// Otherwise, returns the earliest expiration among the blocking holders (zero if none expires)
+// Caller must hold m.mu
+func (m *Manager) tryLocked(keys []string, owner string, ttl time.Duration, now time.Time) (time.Time, bool) {
+	var nextExpiry time.Time
+	free := true
+	for _, key := range keys {
+		h, ok := m.held[key]
+		if !ok {
+			continue
+		}
+		if h.expired(now) {
+			delete(m.held, key)
+			continue
+		}
+		free = false
+		if !h.expireAt.IsZero() && (nextExpiry.IsZero() || h.expireAt.Before(nextExpiry)) {
+			nextExpiry = h.expireAt
+		}
+	}
+	if !free {
+		return nextExpiry, false
+	}
+	for _, key := range keys {
+		m.held[key] = &holder{owner: owner, acquiredAt: now, expireAt: expireAt(now, ttl)}
+	}
+	return time.Time{}, true
+}
+
+// notifyLocked wakes up the waiters
+// Caller must hold m.mu
+func (m *Manager) notifyLocked() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+func expireAt(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+// sortedKeys returns a sorted copy without duplicates
+func sortedKeys(keys []string) []string {
+	sorted := slices.Clone(keys)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
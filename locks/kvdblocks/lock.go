@@ -0,0 +1,145 @@
+package kvdblocks
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+// Lock is a set of keys acquired by Locker
+type Lock struct {
+	locker *Locker
+	keys   []string      // full keys. sorted
+	owner  string        // random owner token stored in the keys
+	token  int64         // fencing token
+	ttl    time.Duration // TTL of acquisition
+}
+
+// Token returns the fencing token. It increases monotonically for each acquisition in the Locker's Namespace,
+// so that a protected resource can reject the writes of a stale owner whose lock has expired
+func (lk *Lock) Token() int64 {
+	return lk.token
+}
+
+// Refresh resets the TTL of all the keys if the lock is still held. ErrLockLost otherwise.
+// ErrInvalidTTL if ttl <= 0, since Expire 0 would delete the keys
+func (lk *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	return lk.ifOwned(ctx, func(b kvdb.Batch) {
+		for _, key := range lk.keys {
+			b.Expire(key, ttl)
+		}
+	})
+}
+
+// Release deletes the keys still held by this lock.
+// ErrLockLost if any of them had expired or been taken; the others are released anyway
+func (lk *Lock) Release(ctx context.Context) error {
+	l := lk.locker
+	for range txRetries {
+		lost := false
+		err := l.client.Watch(ctx, func(tx kvdb.Tx) error {
+			owned, err := lk.ownedKeys(ctx)
+			if err != nil {
+				return err
+			}
+			lost = len(owned) < len(lk.keys)
+			if len(owned) == 0 {
+				return nil
+			}
+			b := tx.TxPipeline()
+			b.Delete(owned...)
+			return b.Exec(ctx)
+		}, lk.keys...)
+		if errors.Is(err, kvdb.ErrTxFailed) {
+			continue
+		}
+		if err == nil && lost {
+			return ErrLockLost
+		}
+		return err
+	}
+	return kvdb.ErrTxFailed
+}
+
+// KeepAlive refreshes the lock with the TTL of acquisition every 1/3 of it until stop is called or parent is done.
+// The returned ctx is cancelled with ErrLockLost as its cause when a refresh finds the lock lost.
+// A refresh failing for other reasons (e.g. network) is retried until the TTL runs out
+func (lk *Lock) KeepAlive(parent context.Context) (ctx context.Context, stop context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	go func() {
+		interval := max(lk.ttl/3, time.Millisecond)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		lastRefreshed := time.Now()
+		for {
+			select {
+			case <-ctx.Done(): // stopped, parent done or lost
+				return
+			case <-ticker.C:
+				err := lk.Refresh(ctx, lk.ttl)
+				switch {
+				case err == nil:
+					lastRefreshed = time.Now()
+				case errors.Is(err, ErrLockLost):
+					cancel(ErrLockLost)
+					return
+				case time.Since(lastRefreshed) >= lk.ttl:
+					log.Printf("[ERROR] lock refresh failed until expiry: %v", err)
+					cancel(ErrLockLost)
+					return
+				default:
+					log.Printf("[WARN] lock refresh failed: %v", err)
+				}
+			}
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// ifOwned queues writes by queue in a transaction, only if all the keys are held by this lock
+func (lk *Lock) ifOwned(ctx context.Context, queue func(b kvdb.Batch)) error {
+	l := lk.locker
+	for range txRetries {
+		err := l.client.Watch(ctx, func(tx kvdb.Tx) error {
+			owned, err := lk.ownedKeys(ctx)
+			if err != nil {
+				return err
+			}
+			if len(owned) < len(lk.keys) {
+				return ErrLockLost
+			}
+			b := tx.TxPipeline()
+			queue(b)
+			return b.Exec(ctx)
+		}, lk.keys...)
+		if !errors.Is(err, kvdb.ErrTxFailed) {
+			return err
+		}
+	}
+	return kvdb.ErrTxFailed
+}
+
+// ownedKeys returns the keys whose values are the owner token of this lock
+func (lk *Lock) ownedKeys(ctx context.Context) ([]string, error) {
+	b := lk.locker.client.Pipeline()
+	vals := make([]*kvdb.Result[string], len(lk.keys))
+	for i, key := range lk.keys {
+		vals[i] = b.Get(key)
+	}
+	if err := b.Exec(ctx); err != nil {
+		return nil, err
+	}
+	owned := make([]string, 0, len(lk.keys))
+	for i, v := range vals {
+		if v.Found() && v.Val() == lk.owner {
+			owned = append(owned, lk.keys[i])
+		}
+	}
+	return owned, nil
+}
@@ -0,0 +1,161 @@
+package kvdblocks
+
+import (
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+const DefaultRetryInterval = 50 * time.Millisecond
+
+// txRetries is the max attempts of a watched transaction before giving up
+const txRetries = 3
+
+var (
+	// errNotAcquired aborts a watched acquisition when any of the keys is held by another owner
+	errNotAcquired = errors.New("kvdblocks: not acquired")
+	// ErrLockLost is returned when any of the keys has expired or is held by another owner
+	ErrLockLost = errors.New("kvdblocks: lock lost")
+	// ErrInvalidTTL is returned for a ttl <= 0, which would keep the keys of a crashed owner forever
+	ErrInvalidTTL = fmt.Errorf("kvdblocks: %w: ttl must be positive", kvdb.ErrInvalidArgument)
+)
+
+// Locker provides locks across processes sharing a kvdb.Client.
+// A lock key holds a random owner token with a TTL, set only if not exists (SET NX PX),
+// and is refreshed or released only by its owner (compare-and-delete).
+// The checks and writes are done in watched transactions (kvdb.Client.Watch) so that
+// multiple keys are acquired all or nothing, on any kvdb backend
+type Locker struct {
+	Namespace     string        // e.g. Core.AppName + "_lock"
+	RetryInterval time.Duration // polling interval of Acquire. jittered. 0 = DefaultRetryInterval
+
+	client kvdb.Client
+}
+
+func NewLocker(client kvdb.Client, namespace string) *Locker {
+	return &Locker{
+		Namespace:     namespace,
+		RetryInterval: DefaultRetryInterval,
+		client:        client,
+	}
+}
+
+func (l *Locker) lockKey(key string) string {
+	return l.Namespace + ":" + key
+}
+
+// metaPrefix is the reserved sub-namespace of the Locker's own keys. Lock keys must not start with it
+const metaPrefix = "_meta:"
+
+// fencingKey holds the counter of the fencing tokens. Shared by all keys so that a token is unique in the Namespace
+func (l *Locker) fencingKey() string {
+	return l.Namespace + ":" + metaPrefix + "fencing"
+}
+
+// TryAcquire acquires all the keys at once for ttl (> 0) without waiting.
+// Returns false if any of them is held by another owner. ErrInvalidTTL if ttl <= 0
+func (l *Locker) TryAcquire(ctx context.Context, ttl time.Duration, keys ...string) (*Lock, bool, error) {
+	if len(keys) == 0 {
+		return nil, false, errors.New("kvdblocks: no keys")
+	}
+	if ttl <= 0 {
+		return nil, false, ErrInvalidTTL
+	}
+	lockKeys := make([]string, len(keys))
+	for i, key := range keys {
+		if strings.HasPrefix(key, metaPrefix) {
+			return nil, false, fmt.Errorf("kvdblocks: %w: key %q is reserved", kvdb.ErrInvalidArgument, key)
+		}
+		lockKeys[i] = l.lockKey(key)
+	}
+	slices.Sort(lockKeys)
+	lockKeys = slices.Compact(lockKeys)
+	owner := crand.Text()
+
+	for range txRetries {
+		var token int64
+		err := l.client.Watch(ctx, func(tx kvdb.Tx) error {
+			b := l.client.Pipeline()
+			exists := make([]*kvdb.Result[bool], len(lockKeys))
+			for i, key := range lockKeys {
+				exists[i] = b.Exists(key)
+			}
+			if err := b.Exec(ctx); err != nil {
+				return err
+			}
+			for _, e := range exists {
+				if e.Val() {
+					return errNotAcquired
+				}
+			}
+			txb := tx.TxPipeline()
+			for _, key := range lockKeys {
+				txb.Set(key, owner, ttl)
+			}
+			fencing := txb.Incr(l.fencingKey())
+			if err := txb.Exec(ctx); err != nil {
+				return err
+			}
+			token = fencing.Val()
+			return nil
+		}, lockKeys...)
+		switch {
+		case err == nil:
+			return &Lock{locker: l, keys: lockKeys, owner: owner, token: token, ttl: ttl}, true, nil
+		case errors.Is(err, errNotAcquired):
+			return nil, false, nil
+		case !errors.Is(err, kvdb.ErrTxFailed):
+			return nil, false, err
+		}
+		// a key changed meanwhile. check again
+	}
+	return nil, false, nil
+}
+
+// Acquire waits until all the keys are acquired at once for ttl, or ctx is done.
+// ttl must be positive. ErrInvalidTTL otherwise
+func (l *Locker) Acquire(ctx context.Context, ttl time.Duration, keys ...string) (*Lock, error) {
+	interval := l.RetryInterval
+	if interval <= 0 {
+		interval = DefaultRetryInterval
+	}
+	for {
+		lock, ok, err := l.TryAcquire(ctx, ttl, keys...)
+		if err != nil || ok {
+			return lock, err
+		}
+		// jitter to spread the contenders
+		wait := interval/2 + rand.N(interval)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Do acquires the keys waiting for them, runs fn renewing the lock, and releases it.
+// fn's ctx is cancelled with ErrLockLost as its cause if the lock is lost while running.
+// token is the fencing token to be passed to the protected resources.
+// ttl must be positive. ErrInvalidTTL otherwise
+func (l *Locker) Do(ctx context.Context, ttl time.Duration, keys []string, fn func(ctx context.Context, token int64) error) error {
+	lock, err := l.Acquire(ctx, ttl, keys...)
+	if err != nil {
+		return err
+	}
+	renewCtx, stop := lock.KeepAlive(ctx)
+	fnErr := fn(renewCtx, lock.Token())
+	stop()
+	// release even if ctx is done
+	if err = lock.Release(context.WithoutCancel(ctx)); err != nil && fnErr == nil {
+		return err
+	}
+	return fnErr
+}
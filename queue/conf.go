@@ -0,0 +1,53 @@
+package queue
+
+import "time"
+
+const (
+	DefaultWorkers           = 1
+	DefaultVisibilityTimeout = 30 * time.Second
+	DefaultMaxAttempts       = 5
+	DefaultBackoff           = time.Second
+	DefaultMaxBackoff        = 5 * time.Minute
+	DefaultPollInterval      = time.Second
+)
+
+// Conf - zero values are replaced with the defaults
+type Conf struct {
+	Workers           int           `json:"workers"`                         // number of concurrent handlers
+	VisibilityTimeout time.Duration `json:"visibility_timeout,format:units"` // a job not acked in this time is retried. handler context deadline
+	MaxAttempts       int           `json:"max_attempts"`                    // attempts before moved to the dead-letter list
+	Backoff           time.Duration `json:"backoff,format:units"`            // retry delay after the 1st failure. doubled for each failure
+	MaxBackoff        time.Duration `json:"max_backoff,format:units"`        // cap of retry delay
+	PollInterval      time.Duration `json:"poll_interval,format:units"`      // idle workers poll and maintenance cycle
+}
+
+func (c Conf) withDefaults() Conf {
+	if c.Workers <= 0 {
+		c.Workers = DefaultWorkers
+	}
+	if c.VisibilityTimeout <= 0 {
+		c.VisibilityTimeout = DefaultVisibilityTimeout
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = DefaultBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	return c
+}
+
+// backoff returns the retry delay after the n-th failure (n >= 1)
+func (c Conf) backoff(n int) time.Duration {
+	d := c.Backoff
+	for i := 1; i < n && d < c.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, c.MaxBackoff)
+}
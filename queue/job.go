@@ -0,0 +1,62 @@
+package queue
+
+import (
+	"context"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"time"
+)
+
+// Job is the envelope of a payload stored in the lists
+type Job struct {
+	ID         string         `json:"id"`
+	Payload    jsontext.Value `json:"payload"`
+	Attempts   int            `json:"attempts"` // failed attempts so far
+	EnqueuedAt time.Time      `json:"enqueued_at"`
+	LastError  string         `json:"last_error,omitempty"`
+
+	raw string // stored form in the in-flight list. to remove it on ack/nack
+}
+
+// Decode unmarshals the payload into v
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+func decodeJob(raw string) (*Job, error) {
+	job := &Job{}
+	if err := json.Unmarshal([]byte(raw), job); err != nil {
+		return nil, err
+	}
+	job.raw = raw
+	return job, nil
+}
+
+func (j *Job) encode() (string, error) {
+	b, err := json.Marshal(j)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Handler processes a job. nil = ack. error = nack (retried with backoff, or dead-lettered after MaxAttempts)
+type Handler func(ctx context.Context, job *Job) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a handler error as not retryable. The job is moved to the dead-letter list at once
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
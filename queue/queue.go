@@ -0,0 +1,336 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+	"github.com/zeptools/gw-core/svc"
+)
+
+// Queue is a durable work queue on kvdb lists, and a service running a pool of workers.
+// Delivery is at-least-once. Handlers should be idempotent
+//
+// Keys under Namespace:
+//   - :ready     list of jobs to be processed
+//   - :inflight  list of jobs being processed. moved atomically from :ready
+//   - :deadlines hash of job ID -> visibility deadline (unix ms) of in-flight jobs
+//   - :delayed   sorted set of jobs to be retried. score = ready time (unix ms)
+//   - :dead      list of jobs failed MaxAttempts times or permanently
+//
+// Without Start, a Queue can be used by producers only (Enqueue).
+type Queue struct {
+	Namespace string // e.g. Core.AppName + "_queue:emails"
+	Conf      Conf
+	Handler   Handler
+
+	Ctx    context.Context    // Service Context. workers stop fetching when cancelled
+	cancel context.CancelFunc // Service Context CancelFunc
+	state  atomic.Int32       // internal service state
+	done   chan error         // Shutdown Error Channel
+	wg     sync.WaitGroup
+	client kvdb.Client
+}
+
+func New(parentCtx context.Context, client kvdb.Client, namespace string, conf Conf, handler Handler) *Queue {
+	svcCtx, svcCancel := context.WithCancel(parentCtx)
+	q := &Queue{
+		Namespace: namespace,
+		Conf:      conf.withDefaults(),
+		Handler:   handler,
+		Ctx:       svcCtx,
+		cancel:    svcCancel,
+		done:      make(chan error, 1),
+		client:    client,
+	}
+	q.state.Store(svc.StateREADY)
+	return q
+}
+
+func (q *Queue) key(suffix string) string {
+	return q.Namespace + ":" + suffix
+}
+
+//---- Producer ----
+
+// Enqueue adds a job with payload marshaled as JSON. Returns the job ID
+func (q *Queue) Enqueue(ctx context.Context, payload any) (string, error) {
+	job, raw, err := newJob(payload)
+	if err != nil {
+		return "", err
+	}
+	if err = q.client.Push(ctx, q.key("ready"), raw); err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// EnqueueIn adds a job to be processed after delay
+func (q *Queue) EnqueueIn(ctx context.Context, payload any, delay time.Duration) (string, error) {
+	job, raw, err := newJob(payload)
+	if err != nil {
+		return "", err
+	}
+	readyAt := time.Now().Add(delay).UnixMilli()
+	if _, err = q.client.ZAdd(ctx, q.key("delayed"), kvdb.ZMember{Score: float64(readyAt), Member: raw}); err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+func newJob(payload any) (*Job, string, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	job := &Job{ID: rand.Text(), Payload: payloadBytes, EnqueuedAt: time.Now()}
+	raw, err := job.encode()
+	return job, raw, err
+}
+
+//---- Consumer ----
+
+// Dequeue moves a job from :ready to :inflight and sets its visibility deadline.
+// The job must be Acked or Nacked before the deadline; otherwise it is retried
+func (q *Queue) Dequeue(ctx context.Context) (*Job, bool, error) {
+	raw, found, err := q.client.PopPush(ctx, q.key("ready"), q.key("inflight"))
+	if err != nil || !found {
+		return nil, false, err
+	}
+	job, err := decodeJob(raw)
+	if err != nil {
+		// not retryable. keep it for inspection
+		log.Printf("[ERROR][Queue] %s: invalid job dead-lettered: %v", q.Namespace, err)
+		b := q.client.TxPipeline()
+		b.Push(q.key("dead"), raw)
+		b.Remove(q.key("inflight"), 1, raw)
+		return nil, false, b.Exec(ctx)
+	}
+	if err = q.client.SetField(ctx, q.key("deadlines"), job.ID, q.deadline()); err != nil {
+		return nil, false, err
+	}
+	return job, true, nil
+}
+
+// Extend pushes back the visibility deadline of an in-flight job for a long-running handler
+func (q *Queue) Extend(ctx context.Context, job *Job, d time.Duration) error {
+	return q.client.SetField(ctx, q.key("deadlines"), job.ID, time.Now().Add(d).UnixMilli())
+}
+
+// Ack removes a processed job
+func (q *Queue) Ack(ctx context.Context, job *Job) error {
+	b := q.client.TxPipeline()
+	b.Remove(q.key("inflight"), 1, job.raw)
+	b.RemoveFields(q.key("deadlines"), job.ID)
+	return b.Exec(ctx)
+}
+
+// Nack schedules a failed job for retry with backoff,
+// or moves it to :dead after MaxAttempts or if cause is Permanent
+func (q *Queue) Nack(ctx context.Context, job *Job, cause error) error {
+	_, err := q.requeue(ctx, job, cause)
+	return err
+}
+
+// requeueTxRetries is the max attempts of requeue when :inflight is modified concurrently, e.g. by other workers dequeuing.
+// A job left in flight after them is retried by ReclaimExpired
+const requeueTxRetries = 10
+
+// requeue moves an in-flight job to :delayed or :dead.
+// Returns false if the job was not in flight any more (acked or reclaimed by another process).
+// :inflight is watched while the job is looked up, and the move and the removal are done in one transaction,
+// so that a crash loses no job and a job acked or reclaimed meanwhile is not requeued twice
+func (q *Queue) requeue(ctx context.Context, job *Job, cause error) (bool, error) {
+	failed := *job
+	failed.Attempts++
+	failed.LastError = cause.Error()
+	raw, err := failed.encode()
+	if err != nil {
+		return false, err
+	}
+	dead := failed.Attempts >= q.Conf.MaxAttempts || isPermanent(cause)
+
+	inflight := false
+	for range requeueTxRetries {
+		err = q.client.Watch(ctx, func(tx kvdb.Tx) error {
+			raws, err := q.client.Range(ctx, q.key("inflight"), 0, -1)
+			if err != nil {
+				return err
+			}
+			if inflight = slices.Contains(raws, job.raw); !inflight {
+				return nil
+			}
+			b := tx.TxPipeline()
+			if dead {
+				b.Push(q.key("dead"), raw)
+			} else {
+				readyAt := time.Now().Add(q.Conf.backoff(failed.Attempts)).UnixMilli()
+				b.ZAdd(q.key("delayed"), kvdb.ZMember{Score: float64(readyAt), Member: raw})
+			}
+			b.Remove(q.key("inflight"), 1, job.raw)
+			b.RemoveFields(q.key("deadlines"), job.ID)
+			return b.Exec(ctx)
+		}, q.key("inflight"))
+		if !errors.Is(err, kvdb.ErrTxFailed) {
+			break
+		}
+	}
+	if err != nil || !inflight {
+		return false, err
+	}
+	if dead {
+		log.Printf("[WARN][Queue] %s: job %s dead-lettered after %d attempts: %v", q.Namespace, job.ID, failed.Attempts, cause)
+	}
+	return true, nil
+}
+
+func (q *Queue) deadline() int64 {
+	return time.Now().Add(q.Conf.VisibilityTimeout).UnixMilli()
+}
+
+//---- Maintenance ----
+
+// ReclaimExpired retries in-flight jobs past their visibility deadline, e.g. of crashed workers.
+// Returns the number of reclaimed jobs
+func (q *Queue) ReclaimExpired(ctx context.Context) (int, error) {
+	raws, err := q.client.Range(ctx, q.key("inflight"), 0, -1)
+	if err != nil {
+		return 0, err
+	}
+	if len(raws) == 0 {
+		return 0, nil
+	}
+	deadlines, err := q.client.GetAllFields(ctx, q.key("deadlines"))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UnixMilli()
+	reclaimed := 0
+	inflightIDs := make(map[string]struct{}, len(raws))
+	for _, raw := range raws {
+		job, err := decodeJob(raw)
+		if err != nil {
+			continue // dead-lettered by Dequeue
+		}
+		inflightIDs[job.ID] = struct{}{}
+		deadline, err := strconv.ParseInt(deadlines[job.ID], 10, 64)
+		if err != nil {
+			// deadline not set yet by Dequeue, or the process crashed before. give it a full timeout from now
+			if err = q.client.SetField(ctx, q.key("deadlines"), job.ID, q.deadline()); err != nil {
+				return reclaimed, err
+			}
+			continue
+		}
+		if now < deadline {
+			continue
+		}
+		ok, err := q.requeue(ctx, job, fmt.Errorf("visibility timeout %v exceeded", q.Conf.VisibilityTimeout))
+		if err != nil {
+			return reclaimed, err
+		}
+		if ok {
+			reclaimed++
+		}
+	}
+	// deadlines left by a reclaim racing with an ack. removed after a grace of a full timeout
+	var orphans []string
+	for id, val := range deadlines {
+		deadline, _ := strconv.ParseInt(val, 10, 64)
+		if _, ok := inflightIDs[id]; !ok && deadline+q.Conf.VisibilityTimeout.Milliseconds() < now {
+			orphans = append(orphans, id)
+		}
+	}
+	if len(orphans) > 0 {
+		if _, err = q.client.RemoveFields(ctx, q.key("deadlines"), orphans...); err != nil {
+			return reclaimed, err
+		}
+	}
+	return reclaimed, nil
+}
+
+// PromoteDelayed moves the delayed jobs which are due to :ready. Returns the number of moved jobs
+func (q *Queue) PromoteDelayed(ctx context.Context) (int, error) {
+	promoted := 0
+	err := q.client.Watch(ctx, func(tx kvdb.Tx) error {
+		due, err := q.client.ZRangeByScore(ctx, q.key("delayed"), math.Inf(-1), float64(time.Now().UnixMilli()), 100)
+		if err != nil || len(due) == 0 {
+			return err
+		}
+		b := tx.TxPipeline()
+		members := make([]string, len(due))
+		for i, m := range due {
+			members[i] = m.Member
+			b.Push(q.key("ready"), m.Member)
+		}
+		b.ZRem(q.key("delayed"), members...)
+		if err = b.Exec(ctx); err != nil {
+			return err
+		}
+		promoted = len(due)
+		return nil
+	}, q.key("delayed"))
+	if errors.Is(err, kvdb.ErrTxFailed) { // promoted by another process. next cycle
+		return 0, nil
+	}
+	return promoted, err
+}
+
+// Stats returns the lengths of the lists
+func (q *Queue) Stats(ctx context.Context) (map[string]int64, error) {
+	b := q.client.Pipeline()
+	ready := b.Len(q.key("ready"))
+	inflight := b.Len(q.key("inflight"))
+	delayed := b.ZCard(q.key("delayed"))
+	dead := b.Len(q.key("dead"))
+	if err := b.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return map[string]int64{
+		"ready":    ready.Val(),
+		"inflight": inflight.Val(),
+		"delayed":  delayed.Val(),
+		"dead":     dead.Val(),
+	}, nil
+}
+
+// RequeueDead moves all dead-lettered jobs back to :ready with their attempts reset. Returns the number of moved jobs
+func (q *Queue) RequeueDead(ctx context.Context) (int, error) {
+	raws, err := q.client.Range(ctx, q.key("dead"), 0, -1)
+	if err != nil {
+		return 0, err
+	}
+	moved := 0
+	for _, raw := range raws {
+		reset := raw
+		if job, err := decodeJob(raw); err == nil {
+			job.Attempts = 0
+			if reset, err = job.encode(); err != nil {
+				return moved, err
+			}
+		}
+		b := q.client.TxPipeline()
+		b.Push(q.key("ready"), reset)
+		removed := b.Remove(q.key("dead"), 1, raw)
+		if err = b.Exec(ctx); err != nil {
+			return moved, err
+		}
+		if removed.Val() == 0 { // requeued by another process. undo
+			if _, err = q.client.Remove(ctx, q.key("ready"), -1, reset); err != nil {
+				return moved, err
+			}
+			continue
+		}
+		moved++
+	}
+	return moved, nil
+}
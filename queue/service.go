@@ -0,0 +1,120 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/zeptools/gw-core/svc"
+)
+
+func (q *Queue) Name() string {
+	return "Queue:" + q.Namespace
+}
+
+// State returns the current service state. svc.StateREADY, svc.StateRUNNING or svc.StateSTOPPED
+func (q *Queue) State() int {
+	return int(q.state.Load())
+}
+
+// Start starts Conf.Workers workers and the maintenance loop (reclaiming expired and promoting delayed jobs)
+func (q *Queue) Start() error {
+	if q.state.Load() == svc.StateRUNNING {
+		return fmt.Errorf("already started")
+	}
+	if q.state.Load() != svc.StateREADY {
+		return fmt.Errorf("cannot start. not ready")
+	}
+	if q.Handler == nil {
+		return fmt.Errorf("no handler")
+	}
+	q.state.Store(svc.StateRUNNING)
+	for range q.Conf.Workers {
+		q.wg.Go(q.runWorker)
+	}
+	q.wg.Go(q.runMaintenance)
+	go func() {
+		q.wg.Wait()
+		log.Printf("[INFO][%s] drained", q.Name())
+		q.done <- nil
+	}()
+	log.Printf("[INFO][%s] service started with %d workers", q.Name(), q.Conf.Workers)
+	return nil
+}
+
+// Stop stops fetching jobs. Jobs being processed are finished before Done
+func (q *Queue) Stop() {
+	if q.state.Load() != svc.StateRUNNING {
+		log.Printf("[ERROR][%s] cannot stop. not running", q.Name())
+		return
+	}
+	q.cancel()
+	q.state.Store(svc.StateSTOPPED)
+	log.Printf("[INFO][%s] service stopped", q.Name())
+}
+
+func (q *Queue) Done() <-chan error {
+	return q.done
+}
+
+func (q *Queue) runWorker() {
+	// kvdb calls of a job in progress must not be cancelled by Stop
+	opCtx := context.WithoutCancel(q.Ctx)
+	for q.Ctx.Err() == nil {
+		job, found, err := q.Dequeue(q.Ctx)
+		if err != nil && q.Ctx.Err() == nil {
+			log.Printf("[ERROR][%s] dequeue failed: %v", q.Name(), err)
+		}
+		if !found {
+			select {
+			case <-q.Ctx.Done():
+			case <-time.After(q.Conf.PollInterval):
+			}
+			continue
+		}
+		if err = q.handle(opCtx, job); err != nil {
+			if nackErr := q.Nack(opCtx, job, err); nackErr != nil {
+				log.Printf("[ERROR][%s] nack %s failed: %v", q.Name(), job.ID, nackErr)
+			}
+			continue
+		}
+		if err = q.Ack(opCtx, job); err != nil {
+			log.Printf("[ERROR][%s] ack %s failed: %v", q.Name(), job.ID, err)
+		}
+	}
+}
+
+// handle runs the Handler with the visibility timeout, recovering a panic into an error
+func (q *Queue) handle(ctx context.Context, job *Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, q.Conf.VisibilityTimeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[PANIC][%s] job %s: %v\n%s", q.Name(), job.ID, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return q.Handler(ctx, job)
+}
+
+func (q *Queue) runMaintenance() {
+	ticker := time.NewTicker(q.Conf.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.Ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := q.ReclaimExpired(q.Ctx); err != nil {
+				log.Printf("[ERROR][%s] reclaim failed: %v", q.Name(), err)
+			} else if n > 0 {
+				log.Printf("[WARN][%s] %d expired jobs reclaimed", q.Name(), n)
+			}
+			if _, err := q.PromoteDelayed(q.Ctx); err != nil {
+				log.Printf("[ERROR][%s] promote failed: %v", q.Name(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package schedjobs
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const DefaultHistorySize = 20
+
+var ErrJobNotFound = errors.New("job not found")
+
+// kinds of JobInfo
+const (
+	KindCron     = "cron"
+	KindInterval = "interval"
+	KindOneTime  = "one-time"
+)
+
+// JobInfo is a snapshot of a job for listings
+type JobInfo struct {
+	ID       string
+	Kind     string // KindCron, KindInterval or KindOneTime
+	Schedule string // cron expression, interval or ExecTime
+	Paused   bool
+	Running  bool
+	LastRun  *Run      // nil = not run in this process
+	NextRun  time.Time // zero = none, e.g. paused
+}
+
+// recordRun keeps run in the history of its job, dropping the oldest beyond HistorySize
+func (s *Scheduler) recordRun(run *Run) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.HistorySize <= 0 {
+		return
+	}
+	if s.history == nil {
+		s.history = make(map[string][]*Run) // safety net
+	}
+	runs := append(s.history[run.JobID], run)
+	if over := len(runs) - s.HistorySize; over > 0 {
+		runs = slices.Delete(runs, 0, over)
+	}
+	s.history[run.JobID] = runs
+}
+
+// History returns the recent runs of the job, newest first
+func (s *Scheduler) History(jobID string) []*Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := slices.Clone(s.history[jobID])
+	slices.Reverse(runs)
+	return runs
+}
+
+// Jobs returns the snapshots of all jobs sorted by kind and ID
+func (s *Scheduler) Jobs() []JobInfo {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var infos []JobInfo
+	for _, job := range s.cronJobs {
+		info := JobInfo{
+			ID:       job.ID,
+			Kind:     KindCron,
+			Schedule: job.Schedule(),
+			Paused:   job.paused,
+			Running:  job.running > 0,
+			LastRun:  s.lastRunLocked(job.ID),
+		}
+		if !job.paused {
+			info.NextRun = job.NextRun(now)
+		}
+		infos = append(infos, info)
+	}
+	for _, t := range s.timers {
+		switch {
+		case t.interval != nil:
+			job := t.interval
+			infos = append(infos, JobInfo{
+				ID:       job.ID,
+				Kind:     KindInterval,
+				Schedule: "every " + job.Every.String(),
+				Running:  job.running,
+				LastRun:  s.lastRunLocked(job.ID),
+				NextRun:  t.at,
+			})
+		case t.oneTime != nil:
+			infos = append(infos, JobInfo{
+				ID:       t.oneTime.ID,
+				Kind:     KindOneTime,
+				Schedule: t.oneTime.ExecTime.Format(time.RFC3339),
+				NextRun:  t.at,
+			})
+		}
+	}
+	slices.SortFunc(infos, func(a, b JobInfo) int {
+		if c := strings.Compare(a.Kind, b.Kind); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return infos
+}
+
+// Caller must hold s.mu
+func (s *Scheduler) lastRunLocked(jobID string) *Run {
+	runs := s.history[jobID]
+	if len(runs) == 0 {
+		return nil
+	}
+	return runs[len(runs)-1]
+}
+
+// PauseCronJob stops running the cron job until ResumeCronJob. A run in progress is not affected
+func (s *Scheduler) PauseCronJob(jobID string) error {
+	return s.setPaused(jobID, true)
+}
+
+func (s *Scheduler) ResumeCronJob(jobID string) error {
+	return s.setPaused(jobID, false)
+}
+
+func (s *Scheduler) setPaused(jobID string, paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.cronJobs[jobID]
+	if !ok {
+		return fmt.Errorf("cron job %q: %w", jobID, ErrJobNotFound)
+	}
+	job.paused = paused
+	return nil
+}
+
+// RunNow runs a job immediately on this instance, even if paused.
+// A cron job runs without the cluster claim, and fails if already running unless its Overlap is OverlapAllow.
+// A one-time job is removed from its schedule
+func (s *Scheduler) RunNow(jobID string) error {
+	now := time.Now()
+	s.mu.Lock()
+	if job, ok := s.cronJobs[jobID]; ok {
+		if job.running > 0 && job.Overlap != OverlapAllow {
+			s.mu.Unlock()
+			return fmt.Errorf("cron job %q already running", jobID)
+		}
+		job.running++
+		s.mu.Unlock()
+		s.wg.Go(func() { s.runCronJobLoop(job, now, false) })
+		return nil
+	}
+	if job, ok := s.intervalJobs[jobID]; ok {
+		s.mu.Unlock()
+		s.startIntervalJob(job, now)
+		return nil
+	}
+	removed := s.removeTimers(func(t *timer) bool { return t.oneTime != nil && t.oneTime.ID == jobID })
+	s.mu.Unlock()
+	if len(removed) == 0 {
+		return fmt.Errorf("job %q: %w", jobID, ErrJobNotFound)
+	}
+	for _, t := range removed {
+		s.runOneTimeJob(t.oneTime)
+	}
+	return nil
+}
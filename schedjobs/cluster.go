@@ -0,0 +1,166 @@
+package schedjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+	"github.com/zeptools/gw-core/db/sqldb"
+)
+
+// ClusterLock elects one instance to run each occurrence of a Singleton cron job among the instances sharing it
+type ClusterLock interface {
+	// Claim claims the occurrence of the job scheduled at the minute for instance.
+	// If already claimed, returns false and the instance which claimed it
+	Claim(ctx context.Context, jobID string, scheduled time.Time, instance string) (claimed bool, owner string, err error)
+}
+
+var ErrOverlapSkipped = errors.New("previous run still running")
+
+// ClaimedError is the reason of an occurrence of a Singleton cron job skipped because another instance runs it
+type ClaimedError struct {
+	Instance string
+}
+
+func (e *ClaimedError) Error() string {
+	return "run by instance " + e.Instance
+}
+
+// DefaultInstance identifies this process in a cluster. hostname:pid
+func DefaultInstance() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return host + ":" + strconv.Itoa(os.Getpid())
+}
+
+// claimOccurrence claims the occurrence of a Singleton cron job. nil if this instance runs it
+func (s *Scheduler) claimOccurrence(job *CronJob, scheduled time.Time) error {
+	if !job.Singleton {
+		return nil
+	}
+	if s.ClusterLock == nil {
+		return errors.New("singleton job without cluster lock")
+	}
+	claimed, owner, err := s.ClusterLock.Claim(s.Ctx, job.ID, scheduled, s.Instance)
+	if err != nil {
+		return fmt.Errorf("cluster claim failed: %w", err)
+	}
+	if !claimed {
+		return &ClaimedError{Instance: owner}
+	}
+	return nil
+}
+
+//---- KV ----
+
+const DefaultClaimRetention = 24 * time.Hour
+
+// KVClusterLock claims an occurrence by SETNX of Namespace:<job ID>:<scheduled unix minute> to the instance
+type KVClusterLock struct {
+	Namespace string        // e.g. Core.AppName + "_cronrun"
+	Retention time.Duration // how long the claims are kept for visibility. 0 = DefaultClaimRetention
+	client    kvdb.Client
+}
+
+// Ensure KVClusterLock implements ClusterLock interface
+var _ ClusterLock = (*KVClusterLock)(nil)
+
+func NewKVClusterLock(client kvdb.Client, namespace string) *KVClusterLock {
+	return &KVClusterLock{Namespace: namespace, Retention: DefaultClaimRetention, client: client}
+}
+
+func (l *KVClusterLock) Claim(ctx context.Context, jobID string, scheduled time.Time, instance string) (bool, string, error) {
+	key := l.Namespace + ":" + jobID + ":" + strconv.FormatInt(scheduled.Unix()/60, 10)
+	retention := l.Retention
+	if retention <= 0 {
+		retention = DefaultClaimRetention
+	}
+	claimed, err := l.client.SetNX(ctx, key, instance, retention)
+	if err != nil || claimed {
+		return claimed, instance, err
+	}
+	owner, _, err := l.client.Get(ctx, key)
+	return false, owner, err
+}
+
+// Owner returns the instance which claimed the occurrence. false if not claimed or expired
+func (l *KVClusterLock) Owner(ctx context.Context, jobID string, scheduled time.Time) (string, bool, error) {
+	return l.client.Get(ctx, l.Namespace+":"+jobID+":"+strconv.FormatInt(scheduled.Unix()/60, 10))
+}
+
+//---- PostgreSQL ----
+
+// PGClusterLock claims an occurrence by recording it in a table, serialized by a transaction-level advisory lock
+// on the job ID and the minute, so that the record also shows which instance ran it.
+// Prune old rows periodically, e.g. with a cron job
+//
+//	CREATE TABLE cron_job_runs (
+//	  job_id       VARCHAR(255) NOT NULL,
+//	  scheduled_at BIGINT       NOT NULL, -- unix ms
+//	  instance     VARCHAR(255) NOT NULL,
+//	  claimed_at   BIGINT       NOT NULL, -- unix ms
+//	  PRIMARY KEY (job_id, scheduled_at)
+//	);
+type PGClusterLock struct {
+	Table  string
+	client sqldb.Client
+}
+
+// Ensure PGClusterLock implements ClusterLock interface
+var _ ClusterLock = (*PGClusterLock)(nil)
+
+func NewPGClusterLock(client sqldb.Client, table string) (*PGClusterLock, error) {
+	if client.Conf().Type != "pgsql" {
+		return nil, fmt.Errorf("advisory locks need pgsql, not %q", client.Conf().Type)
+	}
+	if !sqldb.IdentifierRegexp.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+	return &PGClusterLock{Table: table, client: client}, nil
+}
+
+func (l *PGClusterLock) Claim(ctx context.Context, jobID string, scheduled time.Time, instance string) (bool, string, error) {
+	scheduledAt := scheduled.Truncate(time.Minute).UnixMilli()
+	tx, err := l.client.BeginTx(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	defer tx.Rollback(context.WithoutCancel(ctx)) // no-op after Commit
+	// waits for a concurrent claimer (or a hash collision) to commit. released at the end of the tx
+	lockKey := jobID + ":" + strconv.FormatInt(scheduledAt, 10)
+	if _, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtextextended($1, 0))", lockKey); err != nil {
+		return false, "", err
+	}
+	rows, err := tx.Query(ctx, fmt.Sprintf("SELECT instance FROM %s WHERE job_id = $1 AND scheduled_at = $2", l.Table), jobID, scheduledAt)
+	if err != nil {
+		return false, "", err
+	}
+	var owner string
+	found := rows.Next()
+	if found {
+		err = rows.Scan(&owner)
+	}
+	if closeErr := rows.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, "", err
+	}
+	if found {
+		return false, owner, nil
+	}
+	query := fmt.Sprintf("INSERT INTO %s (job_id, scheduled_at, instance, claimed_at) VALUES ($1, $2, $3, $4)", l.Table)
+	if _, err = tx.Exec(ctx, query, jobID, scheduledAt, instance, time.Now().UnixMilli()); err != nil {
+		return false, "", err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return false, "", err
+	}
+	return true, instance, nil
+}
@@ -1,15 +1,63 @@
 package schedjobs
 
+import (
+	"context"
+	"time"
+)
+
 type CronJob struct {
 	ID          string
 	Minutes     uint64 // 60 bits
 	Hours       uint32 // 24 bits
 	DaysOfMonth uint32 // 31 bits
 	Weekdays    uint8  // 7 bits
-	Task        func() error
+	Months      uint16 // 12 bits. 0 = every month, for jobs built without it
+	// DaysOrWeekdays matches a day satisfying DaysOfMonth OR Weekdays instead of both,
+	// as standard cron does when both fields are restricted (ParseCronJob sets it)
+	DaysOrWeekdays bool
+	// Location is where the wall clock fields are evaluated. nil = Scheduler.Location at AddCronJob, or time.Local
+	Location *time.Location
+	// Task runs with a context cancelled on Scheduler shutdown or after Timeout
+	Task    func(ctx context.Context) error
+	Timeout time.Duration // of each attempt. 0 = no timeout
+	Retry   RetryPolicy
+	Overlap OverlapPolicy // when due while the previous run is still running
+	// Singleton runs each occurrence on one instance among those sharing Scheduler.ClusterLock
+	Singleton bool
 	// Job-specific callbacks
 	OnAdded    func()
-	OnFinished func(error)
+	OnFinished func(run *Run)
+	OnSkipped  func(reason error) // ErrOverlapSkipped, *ClaimedError, or the error of the cluster claim
+
+	// the latest wall clock minute (as UTC) and its zone offset evaluated by the scheduler. see due
+	lastWall   time.Time
+	lastOffset int
+	// runs in progress, the scheduled time of a queued run (zero = none) and paused. guarded by Scheduler.mu
+	running  int
+	queuedAt time.Time
+	paused   bool
+}
+
+// OverlapPolicy decides what happens when a cron job is due while its previous run is still running
+type OverlapPolicy int
+
+const (
+	OverlapAllow    OverlapPolicy = iota // run concurrently
+	OverlapSkip                          // skip the new run
+	OverlapQueueOne                      // run once after the running one finishes. skip the others due meanwhile
+)
+
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapAllow:
+		return "allow"
+	case OverlapSkip:
+		return "skip"
+	case OverlapQueueOne:
+		return "queue-one"
+	default:
+		return "unknown"
+	}
 }
 
 // NewEveryMinEmptyCronJob provides a cronjob matching every minute without a task as a template
@@ -21,7 +69,20 @@ func NewEveryMinEmptyCronJob(jobID string) *CronJob {
 		Hours:       AllHours,
 		DaysOfMonth: AllDaysOfMonth,
 		Weekdays:    AllWeekdays,
+		Months:      AllMonths,
+	}
+}
+
+// Matches reports whether the job's fields match now in the job's Location
+func (job *CronJob) Matches(now time.Time) bool {
+	return job.matchesWall(now.In(job.location()))
+}
+
+func (job *CronJob) location() *time.Location {
+	if job.Location == nil {
+		return time.Local
 	}
+	return job.Location
 }
 
 const (
@@ -29,6 +90,7 @@ const (
 	AllHours       uint32 = 0xFFFFFF          // 24 bits set
 	AllWeekdays    uint8  = 0b01111111        // sun:0b00000001, mon:0b00000010, ..., fri:0b00100000, sat:0b01000000
 	AllDaysOfMonth uint32 = 0x7FFFFFFF        // 31 bits set
+	AllMonths      uint16 = 0xFFF             // 12 bits set. jan:bit 0, ..., dec:bit 11
 )
 
 func BitsFromMinutes(list []int) uint64 {
@@ -70,3 +132,13 @@ func BitsFromDaysOfMonth(list []int) uint32 {
 	}
 	return bits
 }
+
+func BitsFromMonths(list []int) uint16 {
+	var bits uint16
+	for _, v := range list {
+		if v >= 1 && v <= 12 { // month 1 = bit 0
+			bits |= 1 << (v - 1)
+		}
+	}
+	return bits
+}
@@ -6,17 +6,18 @@ import (
 	"time"
 )
 
-func (job *CronJob) Matches(now time.Time) bool {
-	if (job.Minutes & (1 << now.Minute())) == 0 {
+// matchesWall matches the wall clock fields of t as they are, regardless of its location
+func (job *CronJob) matchesWall(t time.Time) bool {
+	if (job.Minutes & (1 << t.Minute())) == 0 {
 		return false
 	}
-	if (job.Hours & (1 << now.Hour())) == 0 {
+	if (job.Hours & (1 << t.Hour())) == 0 {
 		return false
 	}
-	if (job.DaysOfMonth & (1 << (now.Day() - 1))) == 0 { // now.Day() = 1..31 -> bit 0 = day 1
+	if !job.matchesDay(t) {
 		return false
 	}
-	if (job.Weekdays & (1 << now.Weekday())) == 0 {
+	if job.Months != 0 && (job.Months&(1<<(t.Month()-1))) == 0 { // t.Month() = 1..12 -> bit 0 = january
 		return false
 	}
 	return true
@@ -0,0 +1,104 @@
+package schedjobs
+
+import "time"
+
+// Daylight saving time
+//
+// A cron job is evaluated on the wall clock of its Location, so "0 2 * * *" runs at 02:00 local time all year round.
+// On a transition of the wall clock:
+//   - forward (e.g. 02:00 -> 03:00): the skipped minutes are not lost.
+//     If any of them matches, the job runs once at the first minute after the jump,
+//     merged with a run matching that minute itself
+//   - backward (e.g. 02:00 -> 01:00): the repeated minutes are evaluated only for their first occurrence,
+//     so a job does not run twice
+
+// cronSearchYears bounds NextRun for the schedules rarely or never matching, e.g. "0 0 29 2 mon" or "0 0 31 2 *"
+const cronSearchYears = 8
+
+// due reports whether the job runs at the scheduler tick now, applying the DST rules above.
+// Only the scheduler loop calls it
+func (job *CronJob) due(now time.Time) bool {
+	t := now.In(job.location())
+	wall := wallMinute(t)
+	_, offset := t.Zone()
+	last, lastOffset := job.lastWall, job.lastOffset
+	if !last.IsZero() && !wall.After(last) { // repeated by a backward jump (or the same minute ticked twice)
+		return false
+	}
+	job.lastWall, job.lastOffset = wall, offset
+	if job.matchesWall(wall) {
+		return true
+	}
+	if last.IsZero() || offset <= lastOffset {
+		return false
+	}
+	// jumped forward. the minutes in between did not exist
+	for w := last.Add(time.Minute); w.Before(wall); w = w.Add(time.Minute) {
+		if job.matchesWall(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextRun returns the next time strictly after the given time when the job runs, applying the DST rules above.
+// Zero if it never runs (e.g. "0 0 31 2 *")
+func (job *CronJob) NextRun(after time.Time) time.Time {
+	loc := job.location()
+	after = after.In(loc)
+	y, m, d := after.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC) // civil date
+	end := day.AddDate(cronSearchYears, 0, 0)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if job.Months != 0 && job.Months&(1<<(day.Month()-1)) == 0 {
+			continue
+		}
+		if !job.matchesDay(day) {
+			continue
+		}
+		for hour := range 24 {
+			if job.Hours&(1<<hour) == 0 {
+				continue
+			}
+			for minute := range 60 {
+				if job.Minutes&(1<<minute) == 0 {
+					continue
+				}
+				// the second occurrence of a repeated wall clock resolves to the first, skipped if passed
+				if runAt := resolveWall(day.Year(), day.Month(), day.Day(), hour, minute, loc); runAt.After(after) {
+					return runAt
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// resolveWall returns the instant the scheduler runs a job matching the wall clock minute in loc.
+//   - skipped by a forward jump: the end of the gap
+//   - repeated by a backward jump: the first occurrence
+func resolveWall(year int, month time.Month, day, hour, minute int, loc *time.Location) time.Time {
+	wall := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	t := time.Date(year, month, day, hour, minute, 0, 0, loc)
+	start, end := t.ZoneBounds()
+	if w := wallMinute(t); !w.Equal(wall) { // normalized out of a gap, either way
+		if w.After(wall) {
+			return start
+		}
+		return end
+	}
+	_, offset := t.Zone()
+	if !start.IsZero() { // t may be the second occurrence, after a backward jump at start
+		_, prevOffset := start.Add(-time.Second).Zone()
+		if first := t.Add(-time.Duration(prevOffset-offset) * time.Second); prevOffset > offset && first.Before(start) {
+			return first
+		}
+	}
+	return t
+}
+
+// wallMinute returns the wall clock of t truncated to the minute, as UTC
+func wallMinute(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
@@ -0,0 +1,225 @@
+package schedjobs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// 2026 in America/New_York: 02:00 EST -> 03:00 EDT on March 8, 02:00 EDT -> 01:00 EST on November 1
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func mustParseCronJob(t *testing.T, expr string) *CronJob {
+	t.Helper()
+	job, err := ParseCronJob("job", expr)
+	if err != nil {
+		t.Fatalf("ParseCronJob(%q): %v", expr, err)
+	}
+	return job
+}
+
+func utc(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCronJobLocation(t *testing.T) {
+	seoul := mustLoadLocation(t, "Asia/Seoul")
+	tests := []struct {
+		expr string
+		loc  *time.Location
+		at   time.Time
+		want bool
+	}{
+		{"CRON_TZ=Asia/Seoul 0 2 * * *", seoul, utc("2026-01-01T17:00:00Z"), true}, // 02:00 KST
+		{"CRON_TZ=Asia/Seoul 0 2 * * *", seoul, utc("2026-01-01T02:00:00Z"), false},
+		{"TZ=Asia/Seoul 0 2 * * *", seoul, utc("2026-01-01T17:00:00Z"), true},
+		{"TZ=UTC 0 2 * * *", time.UTC, utc("2026-01-01T02:00:00Z"), true},
+		{"  CRON_TZ=UTC   @daily ", time.UTC, utc("2026-01-01T00:00:00Z"), true},
+	}
+	for _, tt := range tests {
+		job := mustParseCronJob(t, tt.expr)
+		if job.Location.String() != tt.loc.String() {
+			t.Errorf("%q: Location = %v, want %v", tt.expr, job.Location, tt.loc)
+		}
+		if got := job.Matches(tt.at); got != tt.want {
+			t.Errorf("%q: Matches(%v) = %v, want %v", tt.expr, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestSchedulerDefaultLocation(t *testing.T) {
+	seoul := mustLoadLocation(t, "Asia/Seoul")
+	s := NewScheduler(context.Background())
+	s.Location = seoul
+	withoutTZ := mustParseCronJob(t, "0 2 * * *")
+	withoutTZ.ID = "without-tz"
+	withTZ := mustParseCronJob(t, "CRON_TZ=UTC 0 2 * * *")
+	withTZ.ID = "with-tz"
+	for _, job := range []*CronJob{withoutTZ, withTZ} {
+		if err := s.AddCronJob(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if withoutTZ.Location != seoul {
+		t.Errorf("job without CRON_TZ: Location = %v, want %v", withoutTZ.Location, seoul)
+	}
+	if withTZ.Location != time.UTC {
+		t.Errorf("job with CRON_TZ: Location = %v, want UTC", withTZ.Location)
+	}
+}
+
+func TestCronJobDueAcrossDST(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	tests := []struct {
+		name     string
+		expr     string
+		from, to time.Time // ticks every minute in [from, to)
+		want     []time.Time
+	}{
+		{
+			name: "skipped hour runs once at the jump",
+			expr: "30 2 * * *",
+			from: utc("2026-03-08T05:00:00Z"), to: utc("2026-03-08T09:00:00Z"),
+			want: []time.Time{utc("2026-03-08T07:00:00Z")}, // 03:00 EDT
+		},
+		{
+			name: "skipped minutes merged with the minute after the jump",
+			expr: "0,30 2,3 * * *",
+			from: utc("2026-03-08T05:00:00Z"), to: utc("2026-03-08T09:00:00Z"),
+			want: []time.Time{utc("2026-03-08T07:00:00Z"), utc("2026-03-08T07:30:00Z")}, // 03:00, 03:30 EDT
+		},
+		{
+			name: "hour after the gap is not affected",
+			expr: "0 3 * * *",
+			from: utc("2026-03-08T05:00:00Z"), to: utc("2026-03-08T09:00:00Z"),
+			want: []time.Time{utc("2026-03-08T07:00:00Z")},
+		},
+		{
+			name: "repeated hour runs only for the first occurrence",
+			expr: "30 1 * * *",
+			from: utc("2026-11-01T04:00:00Z"), to: utc("2026-11-01T08:00:00Z"),
+			want: []time.Time{utc("2026-11-01T05:30:00Z")}, // 01:30 EDT
+		},
+		{
+			name: "every 30 minutes across the repeated hour",
+			expr: "*/30 * * * *",
+			from: utc("2026-11-01T04:00:00Z"), to: utc("2026-11-01T08:00:00Z"),
+			want: []time.Time{
+				utc("2026-11-01T04:00:00Z"), // 00:00 EDT
+				utc("2026-11-01T04:30:00Z"),
+				utc("2026-11-01T05:00:00Z"), // 01:00 EDT
+				utc("2026-11-01T05:30:00Z"),
+				utc("2026-11-01T07:00:00Z"), // 02:00 EST. 01:00 and 01:30 EST are repeated
+				utc("2026-11-01T07:30:00Z"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := mustParseCronJob(t, tt.expr)
+			job.Location = newYork
+			var got []time.Time
+			for now := tt.from; now.Before(tt.to); now = now.Add(time.Minute) {
+				if job.due(now.Add(time.Second)) { // ticks land a little after the minute
+					got = append(got, now)
+				}
+			}
+			assertTimes(t, got, tt.want)
+		})
+	}
+}
+
+func TestCronJobDueSameMinuteOnce(t *testing.T) {
+	job := mustParseCronJob(t, "CRON_TZ=UTC * * * * *")
+	now := utc("2026-01-01T00:00:00Z")
+	if !job.due(now) {
+		t.Fatal("first tick not due")
+	}
+	if job.due(now.Add(30 * time.Second)) {
+		t.Error("second tick in the same minute due")
+	}
+	if !job.due(now.Add(time.Minute)) {
+		t.Error("next minute not due")
+	}
+}
+
+func TestCronJobNextRun(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"before the gap", "30 2 * * *", utc("2026-03-07T17:00:00Z"), utc("2026-03-08T07:00:00Z")},         // skipped 02:30 -> 03:00 EDT
+		{"after the gap", "30 2 * * *", utc("2026-03-08T07:00:00Z"), utc("2026-03-09T06:30:00Z")},          // 02:30 EDT
+		{"hour after the gap", "0 3 * * *", utc("2026-03-07T17:00:00Z"), utc("2026-03-08T07:00:00Z")},      // 03:00 EDT
+		{"repeated hour", "30 1 * * *", utc("2026-10-31T16:00:00Z"), utc("2026-11-01T05:30:00Z")},          // 01:30 EDT
+		{"repeated hour passed", "30 1 * * *", utc("2026-11-01T05:30:00Z"), utc("2026-11-02T06:30:00Z")},   // not 01:30 EST
+		{"within repeated hour", "*/30 * * * *", utc("2026-11-01T05:30:00Z"), utc("2026-11-01T07:00:00Z")}, // 02:00 EST
+		{"strictly after", "0 12 * * *", utc("2026-06-01T16:00:00Z"), utc("2026-06-02T16:00:00Z")},
+		{"month and weekday", "0 9 * jan mon", utc("2026-06-01T00:00:00Z"), utc("2027-01-04T14:00:00Z")},
+		{"leap day", "0 0 29 2 *", utc("2026-01-01T00:00:00Z"), utc("2028-02-29T05:00:00Z")},
+		{"never", "0 0 31 2 *", utc("2026-01-01T00:00:00Z"), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := mustParseCronJob(t, tt.expr)
+			job.Location = newYork
+			if got := job.NextRun(tt.after); !got.Equal(tt.want) {
+				t.Errorf("NextRun(%v) = %v, want %v", tt.after, got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+// NextRun agrees with the scheduler ticking every minute, including across the DST transitions
+func TestCronJobNextRunMatchesDue(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	exprs := []string{"30 2 * * *", "0,30 1-3 * * *", "*/20 * * * *", "0 0 1 * *"}
+	windows := [][2]time.Time{
+		{utc("2026-03-07T00:00:00Z"), utc("2026-03-10T00:00:00Z")},
+		{utc("2026-10-31T00:00:00Z"), utc("2026-11-03T00:00:00Z")},
+	}
+	for _, expr := range exprs {
+		for _, w := range windows {
+			job := mustParseCronJob(t, expr)
+			job.Location = newYork
+			var ticked []time.Time
+			for now := w[0]; now.Before(w[1]); now = now.Add(time.Minute) {
+				if job.due(now) {
+					ticked = append(ticked, now)
+				}
+			}
+			var next []time.Time
+			for at := job.NextRun(w[0].Add(-time.Second)); !at.IsZero() && at.Before(w[1]); at = job.NextRun(at) {
+				next = append(next, at)
+			}
+			assertTimes(t, next, ticked)
+		}
+	}
+}
+
+func assertTimes(t *testing.T, got, want []time.Time) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d times %v, want %d %v", len(got), got, len(want), want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("[%d] = %v, want %v", i, got[i].UTC(), want[i])
+		}
+	}
+}
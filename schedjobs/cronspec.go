@@ -0,0 +1,282 @@
+package schedjobs
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros are the predefined schedules
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// cronField is the value range of a field
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField     = cronField{name: "minute", min: 0, max: 59}
+	hourField       = cronField{name: "hour", min: 0, max: 23}
+	dayOfMonthField = cronField{name: "day of month", min: 1, max: 31}
+	monthField      = cronField{name: "month", min: 1, max: 12, names: monthNames}
+	weekdayField    = cronField{name: "weekday", min: 0, max: 7, names: weekdayNames} // 7 = sunday
+)
+
+// ParseCronJob provides a cron job without a task from a standard 5-field cron expression
+// "minute hour day-of-month month weekday", or a macro (@yearly, @annually, @monthly, @weekly, @daily, @midnight, @hourly).
+// A field is a comma-separated list of *, a value, or a range a-b, each optionally with a step /n.
+// a/n means a-max/n. Months and weekdays also take names (jan-dec, sun-sat). Weekday 7 is sunday.
+// As standard cron, if both day of month and weekday are restricted (not starting with *), a day matching either runs the job.
+// A prefix CRON_TZ=<IANA name> (or TZ=) sets the Location, e.g. "CRON_TZ=Asia/Seoul 0 2 * * *"
+func ParseCronJob(jobID string, expr string) (*CronJob, error) {
+	job := &CronJob{ID: jobID}
+	if err := job.SetSchedule(expr); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// SetSchedule replaces the time condition of the job with a cron expression. See ParseCronJob
+func (job *CronJob) SetSchedule(expr string) error {
+	expr = strings.TrimSpace(expr)
+	var loc *time.Location
+	if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+		tz, rest, _ := strings.Cut(expr, " ")
+		_, name, _ := strings.Cut(tz, "=")
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			return fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+		expr = strings.TrimSpace(rest)
+	}
+	if strings.HasPrefix(expr, "@") {
+		macro, ok := cronMacros[strings.ToLower(expr)]
+		if !ok {
+			return fmt.Errorf("unknown cron macro %q", expr)
+		}
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return fmt.Errorf("cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+	minutes, err := minuteField.parse(fields[0])
+	if err != nil {
+		return err
+	}
+	hours, err := hourField.parse(fields[1])
+	if err != nil {
+		return err
+	}
+	days, err := dayOfMonthField.parse(fields[2])
+	if err != nil {
+		return err
+	}
+	months, err := monthField.parse(fields[3])
+	if err != nil {
+		return err
+	}
+	weekdays, err := weekdayField.parse(fields[4])
+	if err != nil {
+		return err
+	}
+	if weekdays&(1<<7) != 0 { // 7 -> 0 (sunday)
+		weekdays = weekdays&^(1<<7) | 1
+	}
+	job.Minutes = minutes
+	job.Hours = uint32(hours)
+	job.DaysOfMonth = uint32(days >> 1) // day 1 = bit 0
+	job.Months = uint16(months >> 1)    // january = bit 0
+	job.Weekdays = uint8(weekdays)
+	job.DaysOrWeekdays = !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*")
+	if loc != nil {
+		job.Location = loc
+	}
+	return nil
+}
+
+// parse returns the bits of the values in the field. bit n = value n
+func (f cronField) parse(field string) (uint64, error) {
+	var set uint64
+	for item := range strings.SplitSeq(field, ",") {
+		bits, err := f.parseItem(item)
+		if err != nil {
+			return 0, fmt.Errorf("cron %s field %q: %w", f.name, field, err)
+		}
+		set |= bits
+	}
+	return set, nil
+}
+
+func (f cronField) parseItem(item string) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(item, "/")
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepPart)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid step %q", stepPart)
+		}
+		step = n
+	}
+	var lo, hi int
+	switch {
+	case rangePart == "*":
+		lo, hi = f.min, f.max
+		if f.max == 7 { // weekday. * = sun-sat without the alias 7
+			hi = 6
+		}
+	case strings.Contains(rangePart, "-"):
+		loPart, hiPart, _ := strings.Cut(rangePart, "-")
+		var err error
+		if lo, err = f.value(loPart); err != nil {
+			return 0, err
+		}
+		if hi, err = f.value(hiPart); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("invalid range %q", rangePart)
+		}
+	default:
+		var err error
+		if lo, err = f.value(rangePart); err != nil {
+			return 0, err
+		}
+		hi = lo
+		if hasStep { // a/n = a-max/n
+			hi = f.max
+		}
+	}
+	var set uint64
+	for v := lo; v <= hi; v += step {
+		set |= 1 << v
+	}
+	return set, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Schedule renders the time condition as a 5-field cron expression, e.g. for logs.
+// Prefixed with CRON_TZ=<name> if the Location is set
+func (job *CronJob) Schedule() string {
+	if job.Location != nil {
+		return "CRON_TZ=" + job.Location.String() + " " + job.schedule()
+	}
+	return job.schedule()
+}
+
+func (job *CronJob) schedule() string {
+	months := job.Months
+	if months == 0 {
+		months = AllMonths
+	}
+	fields := []string{
+		formatCronField(job.Minutes, 0, 59),
+		formatCronField(uint64(job.Hours), 0, 23),
+		formatCronField(uint64(job.DaysOfMonth)<<1, 1, 31),
+		formatCronField(uint64(months)<<1, 1, 12),
+		formatCronField(uint64(job.Weekdays), 0, 6),
+	}
+	// a restricted field is rendered without a leading * so that it parses back to the same day matching
+	if !job.DaysOrWeekdays {
+		if fields[2] != "*" && fields[4] != "*" {
+			// AND of both restricted is not expressible in standard cron. keep the fields as they are
+			return strings.Join(fields, " ") + " (day of month AND weekday)"
+		}
+	} else {
+		if fields[2] == "*" {
+			fields[2] = "1-31"
+		}
+		if fields[4] == "*" {
+			fields[4] = "0-6"
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// formatCronField renders bits of values min..max (bit n = value n) as *, */n or a list of values and ranges
+func formatCronField(set uint64, min, max int) string {
+	all := uint64(1)<<(max+1) - uint64(1)<<min
+	set &= all
+	if set == all {
+		return "*"
+	}
+	if set == 0 {
+		return "-" // never matches
+	}
+	// */n
+	if n := bits.OnesCount64(set); n > 2 && set&(1<<min) != 0 {
+		step := bits.TrailingZeros64(set>>(min+1)) + 1
+		var stepped uint64
+		for v := min; v <= max; v += step {
+			stepped |= 1 << v
+		}
+		if stepped == set {
+			return "*/" + strconv.Itoa(step)
+		}
+	}
+	var parts []string
+	for v := min; v <= max; v++ {
+		if set&(1<<v) == 0 {
+			continue
+		}
+		end := v
+		for end < max && set&(1<<(end+1)) != 0 {
+			end++
+		}
+		switch {
+		case end == v:
+			parts = append(parts, strconv.Itoa(v))
+		case end == v+1:
+			parts = append(parts, strconv.Itoa(v), strconv.Itoa(end))
+		default:
+			parts = append(parts, strconv.Itoa(v)+"-"+strconv.Itoa(end))
+		}
+		v = end
+	}
+	return strings.Join(parts, ",")
+}
+
+// matchesDay matches the day of month and the weekday of t, either or both by DaysOrWeekdays
+func (job *CronJob) matchesDay(t time.Time) bool {
+	dayOK := (job.DaysOfMonth & (1 << (t.Day() - 1))) != 0 // t.Day() = 1..31 -> bit 0 = day 1
+	weekdayOK := (job.Weekdays & (1 << t.Weekday())) != 0
+	if job.DaysOrWeekdays {
+		return dayOK || weekdayOK
+	}
+	return dayOK && weekdayOK
+}
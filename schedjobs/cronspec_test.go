@@ -0,0 +1,221 @@
+package schedjobs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCronJob(t *testing.T) {
+	tests := []struct {
+		expr           string
+		minutes        []int
+		hours          []int
+		days           []int
+		months         []int
+		weekdays       []int
+		daysOrWeekdays bool
+	}{
+		{"0 0 * * 7", []int{0}, []int{0}, nil, nil, []int{0}, false},
+		{"0 0 * * 0", []int{0}, []int{0}, nil, nil, []int{0}, false},
+		{"0 0 * * 5-7", []int{0}, []int{0}, nil, nil, []int{0, 5, 6}, false},
+		{"0 0 * * sun", []int{0}, []int{0}, nil, nil, []int{0}, false},
+		{"0 0 * * MON-fri", []int{0}, []int{0}, nil, nil, []int{1, 2, 3, 4, 5}, false},
+		{"0 0 1 jan *", []int{0}, []int{0}, []int{1}, []int{1}, nil, false},
+		{"0 0 1 Jan-Mar,dec *", []int{0}, []int{0}, []int{1}, []int{1, 2, 3, 12}, nil, false},
+		{"*/15 * * * *", []int{0, 15, 30, 45}, nil, nil, nil, nil, false},
+		{"5/20 * * * *", []int{5, 25, 45}, nil, nil, nil, nil, false},
+		{"0 9-17/4 * * *", []int{0}, []int{9, 13, 17}, nil, nil, nil, false},
+		{"0,30 8,20 * * *", []int{0, 30}, []int{8, 20}, nil, nil, nil, false},
+		{"0 0 1,15 * mon", []int{0}, []int{0}, []int{1, 15}, nil, []int{1}, true},
+		{"0 0 */2 * mon", []int{0}, []int{0}, []int{1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23, 25, 27, 29, 31}, nil, []int{1}, false},
+		{"0 0 1 * */2", []int{0}, []int{0}, []int{1}, nil, []int{0, 2, 4, 6}, false},
+		{"@yearly", []int{0}, []int{0}, []int{1}, []int{1}, nil, false},
+		{"@annually", []int{0}, []int{0}, []int{1}, []int{1}, nil, false},
+		{"@monthly", []int{0}, []int{0}, []int{1}, nil, nil, false},
+		{"@weekly", []int{0}, []int{0}, nil, nil, []int{0}, false},
+		{"@daily", []int{0}, []int{0}, nil, nil, nil, false},
+		{"@midnight", []int{0}, []int{0}, nil, nil, nil, false},
+		{"@HOURLY", []int{0}, nil, nil, nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			job, err := ParseCronJob("job", tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCronJob(%q): %v", tt.expr, err)
+			}
+			if want := bitsOr(BitsFromMinutes(tt.minutes), AllMinutes, tt.minutes); job.Minutes != want {
+				t.Errorf("Minutes = %b, want %b", job.Minutes, want)
+			}
+			if want := bitsOr(BitsFromHours(tt.hours), AllHours, tt.hours); job.Hours != want {
+				t.Errorf("Hours = %b, want %b", job.Hours, want)
+			}
+			if want := bitsOr(BitsFromDaysOfMonth(tt.days), AllDaysOfMonth, tt.days); job.DaysOfMonth != want {
+				t.Errorf("DaysOfMonth = %b, want %b", job.DaysOfMonth, want)
+			}
+			if want := bitsOr(BitsFromMonths(tt.months), AllMonths, tt.months); job.Months != want {
+				t.Errorf("Months = %b, want %b", job.Months, want)
+			}
+			if want := bitsOr(BitsFromWeekdays(tt.weekdays), AllWeekdays, tt.weekdays); job.Weekdays != want {
+				t.Errorf("Weekdays = %b, want %b", job.Weekdays, want)
+			}
+			if job.DaysOrWeekdays != tt.daysOrWeekdays {
+				t.Errorf("DaysOrWeekdays = %v, want %v", job.DaysOrWeekdays, tt.daysOrWeekdays)
+			}
+		})
+	}
+}
+
+// bitsOr returns all if list is nil (the field is *), bits otherwise
+func bitsOr[T uint8 | uint16 | uint32 | uint64](bits T, all T, list []int) T {
+	if list == nil {
+		return all
+	}
+	return bits
+}
+
+func TestParseCronJobErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"0 0 * *",
+		"0 0 * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"* * * foo *",
+		"@every",
+		"CRON_TZ=Nowhere/Nothing 0 0 * * *",
+	}
+	for _, expr := range tests {
+		if job, err := ParseCronJob("job", expr); err == nil {
+			t.Errorf("ParseCronJob(%q) = %q, want error", expr, job.Schedule())
+		}
+	}
+}
+
+func TestCronJobMatchesDay(t *testing.T) {
+	orJob, err := ParseCronJob("job", "0 0 13 * fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orJob.Location = time.UTC
+	andJob := *orJob
+	andJob.DaysOrWeekdays = false
+	tests := []struct {
+		date    string
+		wantOR  bool
+		wantAND bool
+	}{
+		{"2026-03-13", true, true},   // friday the 13th
+		{"2026-03-06", true, false},  // friday
+		{"2026-04-13", true, false},  // monday the 13th
+		{"2026-04-14", false, false}, // tuesday
+	}
+	for _, tt := range tests {
+		day, err := time.Parse(time.DateOnly, tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := orJob.Matches(day); got != tt.wantOR {
+			t.Errorf("OR Matches(%s) = %v, want %v", tt.date, got, tt.wantOR)
+		}
+		if got := andJob.Matches(day); got != tt.wantAND {
+			t.Errorf("AND Matches(%s) = %v, want %v", tt.date, got, tt.wantAND)
+		}
+	}
+}
+
+func TestCronJobSchedule(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"* * * * *", "* * * * *"},
+		{"0 0 * * 7", "0 0 * * 0"},
+		{"0 0 * * 5-7", "0 0 * * 0,5,6"},
+		{"0 0 1 jan *", "0 0 1 1 *"},
+		{"*/15 9-17 * * mon-fri", "*/15 9-17 * * 1-5"},
+		{"0,30 8,20 * * *", "0,30 8,20 * * *"},
+		{"0 0 1,15 * mon", "0 0 1,15 * 1"},
+		{"0 0 1 * *", "0 0 1 * *"},
+		{"0 0 */2 * mon", "0 0 */2 * 1 (day of month AND weekday)"},
+		{"@weekly", "0 0 * * 0"},
+		{"CRON_TZ=Asia/Seoul 0 2 * * *", "CRON_TZ=Asia/Seoul 0 2 * * *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			job, err := ParseCronJob("job", tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCronJob(%q): %v", tt.expr, err)
+			}
+			if got := job.Schedule(); got != tt.want {
+				t.Errorf("Schedule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronJobScheduleRoundTrip(t *testing.T) {
+	tests := []string{
+		"* * * * *",
+		"0 0 * * 7",
+		"0 0 1 jan *",
+		"*/15 9-17 * * mon-fri",
+		"5/20 */3 * * *",
+		"0 0 1,15 * mon",
+		"0 0 1-7 * sat,sun",
+		"0 0 * feb-apr,oct *",
+		"30 2 * * *",
+		"@yearly",
+		"@hourly",
+		"CRON_TZ=America/New_York 30 2 * * *",
+		"TZ=UTC 0 0 * * *",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			job, err := ParseCronJob("job", expr)
+			if err != nil {
+				t.Fatalf("ParseCronJob(%q): %v", expr, err)
+			}
+			rendered := job.Schedule()
+			again, err := ParseCronJob("job", rendered)
+			if err != nil {
+				t.Fatalf("ParseCronJob(Schedule() = %q): %v", rendered, err)
+			}
+			assertSameSchedule(t, again, job)
+		})
+	}
+}
+
+func TestCronJobScheduleBuiltByHand(t *testing.T) {
+	job := NewEveryMinEmptyCronJob("job")
+	job.Minutes = BitsFromMinutes([]int{0})
+	job.Hours = BitsFromHours([]int{2})
+	job.Months = 0 // built without the month field
+	if got, want := job.Schedule(), "0 2 * * *"; got != want {
+		t.Errorf("Schedule() = %q, want %q", got, want)
+	}
+	// AND of both restricted day fields cannot be written in standard cron
+	job.DaysOfMonth = BitsFromDaysOfMonth([]int{13})
+	job.Weekdays = BitsFromWeekdays([]int{5})
+	if got := job.Schedule(); !strings.HasSuffix(got, "(day of month AND weekday)") {
+		t.Errorf("Schedule() = %q, want the AND note", got)
+	}
+}
+
+func assertSameSchedule(t *testing.T, got, want *CronJob) {
+	t.Helper()
+	if got.Minutes != want.Minutes || got.Hours != want.Hours || got.DaysOfMonth != want.DaysOfMonth ||
+		got.Months != want.Months || got.Weekdays != want.Weekdays || got.DaysOrWeekdays != want.DaysOrWeekdays {
+		t.Errorf("schedule %q != %q", got.Schedule(), want.Schedule())
+	}
+	if got.location().String() != want.location().String() {
+		t.Errorf("Location = %v, want %v", got.location(), want.location())
+	}
+}
@@ -0,0 +1,25 @@
+package schedjobs
+
+import (
+	"context"
+	"time"
+)
+
+// IntervalJob runs at a fixed rate. The runs are scheduled from the first one by Every, so that they do not drift,
+// and those missed (e.g. by a sleep of the host) are skipped. A run is skipped while the previous one is still running
+type IntervalJob struct {
+	ID           string
+	Every        time.Duration
+	InitialDelay time.Duration // the first run after AddIntervalJob. 0 = immediately (or at Start)
+	Jitter       time.Duration // adds a random delay in [0, Jitter) to each run, to spread instances
+	// Task runs with a context cancelled on Scheduler shutdown or after Timeout
+	Task    func(ctx context.Context) error
+	Timeout time.Duration // of each attempt. 0 = no timeout
+	Retry   RetryPolicy
+	// Job-specific callbacks
+	OnAdded    func()
+	OnFinished func(run *Run)
+	OnSkipped  func(reason error) // ErrOverlapSkipped
+
+	running bool // guarded by Scheduler.mu
+}
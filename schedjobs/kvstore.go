@@ -0,0 +1,73 @@
+package schedjobs
+
+import (
+	"context"
+	"encoding/json/v2"
+	"errors"
+	"log"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+// saveTxRetries is the max attempts of Save when the hash is modified concurrently
+const saveTxRetries = 5
+
+// KVJobStore stores the jobs as JSON in a hash of Key by job ID
+type KVJobStore struct {
+	Key    string // e.g. Core.AppName + "_jobs:onetime"
+	client kvdb.Client
+}
+
+// Ensure KVJobStore implements JobStore interface
+var _ JobStore = (*KVJobStore)(nil)
+
+func NewKVJobStore(client kvdb.Client, key string) *KVJobStore {
+	return &KVJobStore{Key: key, client: client}
+}
+
+func (st *KVJobStore) Save(ctx context.Context, job *StoredJob) error {
+	b, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	for range saveTxRetries {
+		err = st.client.Watch(ctx, func(tx kvdb.Tx) error {
+			_, found, err := st.client.GetField(ctx, st.Key, job.ID)
+			if err != nil {
+				return err
+			}
+			if found {
+				return ErrJobExists
+			}
+			txb := tx.TxPipeline()
+			txb.SetField(st.Key, job.ID, string(b))
+			return txb.Exec(ctx)
+		}, st.Key)
+		if !errors.Is(err, kvdb.ErrTxFailed) {
+			return err
+		}
+	}
+	return err
+}
+
+func (st *KVJobStore) Delete(ctx context.Context, id string) (bool, error) {
+	n, err := st.client.RemoveFields(ctx, st.Key, id)
+	return n > 0, err
+}
+
+func (st *KVJobStore) List(ctx context.Context) ([]*StoredJob, error) {
+	fields, err := st.client.GetAllFields(ctx, st.Key)
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]*StoredJob, 0, len(fields))
+	for id, raw := range fields {
+		job := &StoredJob{}
+		if err = json.Unmarshal([]byte(raw), job); err != nil {
+			log.Printf("[ERROR][JobScheduler] invalid stored job %s: %v", id, err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
@@ -1,12 +1,20 @@
 package schedjobs
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type OneTimeJob struct {
 	ID       string
 	ExecTime time.Time
-	Task     func() error
+	// Task runs with a context cancelled on Scheduler shutdown or after Timeout
+	Task    func(ctx context.Context) error
+	Timeout time.Duration // of each attempt. 0 = no timeout
+	Retry   RetryPolicy
 	// Job-specific callbacks
 	OnAdded    func()
-	OnFinished func(error)
+	OnFinished func(run *Run)
+
+	stored *StoredJob // persisted in Scheduler.Store. nil = in memory only
 }
@@ -0,0 +1,103 @@
+package schedjobs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const DefaultRetryBackoff = time.Second
+
+// RetryPolicy retries a failed run of a task. The zero value does not retry
+type RetryPolicy struct {
+	MaxAttempts int           // including the first. <= 1 = no retry
+	Backoff     time.Duration // wait before the 2nd attempt, doubled for each next. 0 = DefaultRetryBackoff
+	MaxBackoff  time.Duration // 0 = no limit
+	Jitter      float64       // randomizes each wait by ±Jitter of it, e.g. 0.2
+	// Retryable decides whether an error is retried. nil = any error (including a recovered panic)
+	Retryable func(err error) bool
+}
+
+// backoff returns the wait after the failed attempt n (1-based)
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.Backoff
+	if d <= 0 {
+		d = DefaultRetryBackoff
+	}
+	for range n - 1 {
+		// stop doubling before it overflows into a busy retry loop, leaving room for the jitter
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff || d > math.MaxInt64/4 {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxBackoff > 0 {
+		d = min(d, p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	return max(d, 0)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable == nil {
+		return true
+	}
+	return p.Retryable(err)
+}
+
+// Run is the record of a run of a task, passed to the finished callbacks
+type Run struct {
+	JobID     string
+	Scheduled time.Time // the minute of the occurrence for a cron job, ExecTime for a one-time job
+	Instance  string    // Scheduler.Instance which ran it
+	Attempt   int       // the last attempt, from 1
+	Start     time.Time // start of the first attempt
+	End       time.Time // end of the last attempt
+	Err       error     // error of the last attempt. nil = succeeded
+}
+
+func (r *Run) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// PanicError is a panic in a task recovered as its error
+// Error is a single line for logs and run histories. The stack is logged on recovery and kept in Stack
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// execute runs task with timeout for each attempt and retries it by policy, until parent is done
+func execute(parent context.Context, jobID string, task func(ctx context.Context) error, timeout time.Duration, policy RetryPolicy) *Run {
+	run := &Run{JobID: jobID, Start: time.Now()}
+	for {
+		run.Attempt++
+		run.Err = runTask(parent, task, timeout)
+		run.End = time.Now()
+		if run.Err == nil || run.Attempt >= policy.MaxAttempts || !policy.retryable(run.Err) {
+			return run
+		}
+		wait := time.NewTimer(policy.backoff(run.Attempt))
+		select {
+		case <-parent.Done():
+			wait.Stop()
+			run.Err = fmt.Errorf("retry abandoned on shutdown: %w", run.Err)
+			return run
+		case <-wait.C:
+		}
+	}
+}
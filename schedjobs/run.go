@@ -0,0 +1,193 @@
+package schedjobs
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"runtime/debug"
+	"time"
+)
+
+// startCronJob runs the occurrence of the job scheduled at the minute in a goroutine, or skips or queues it by its Overlap policy
+func (s *Scheduler) startCronJob(job *CronJob, scheduled time.Time) {
+	s.mu.Lock()
+	if job.running > 0 {
+		switch job.Overlap {
+		case OverlapSkip:
+			s.mu.Unlock()
+			s.cronJobSkipped(job, ErrOverlapSkipped)
+			return
+		case OverlapQueueOne:
+			queued := !job.queuedAt.IsZero()
+			job.queuedAt = scheduled
+			s.mu.Unlock()
+			if queued { // replaced by this one
+				s.cronJobSkipped(job, ErrOverlapSkipped)
+			}
+			return
+		}
+	}
+	job.running++
+	s.mu.Unlock()
+	s.wg.Go(func() { s.runCronJobLoop(job, scheduled, true) })
+}
+
+// runCronJobLoop runs an occurrence of the job and then the queued ones. claim = false for a manual run
+func (s *Scheduler) runCronJobLoop(job *CronJob, scheduled time.Time, claim bool) {
+	for {
+		s.runCronJobOccurrence(job, scheduled, claim)
+		claim = true
+		// the queued run continues in this goroutine
+		s.mu.Lock()
+		scheduled = job.queuedAt
+		job.queuedAt = time.Time{}
+		if scheduled.IsZero() || s.Ctx.Err() != nil {
+			job.running--
+			s.mu.Unlock()
+			if !scheduled.IsZero() { // shutting down
+				s.cronJobSkipped(job, context.Cause(s.Ctx))
+			}
+			return
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Scheduler) runCronJobOccurrence(job *CronJob, scheduled time.Time, claim bool) {
+	if claim {
+		if err := s.claimOccurrence(job, scheduled); err != nil {
+			s.cronJobSkipped(job, err)
+			return
+		}
+	}
+	run := execute(s.Ctx, job.ID, job.Task, job.Timeout, job.Retry)
+	run.Scheduled, run.Instance = scheduled, s.Instance
+	s.cronJobFinished(job, run)
+}
+
+// startIntervalJob runs the job in a goroutine unless the previous run is still running
+func (s *Scheduler) startIntervalJob(job *IntervalJob, scheduled time.Time) {
+	s.mu.Lock()
+	if job.running {
+		s.mu.Unlock()
+		if job.OnSkipped != nil {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						log.Println("[PANIC] Recovered in job.OnSkipped:", r)
+					}
+				}()
+				job.OnSkipped(ErrOverlapSkipped)
+			}()
+		}
+		return
+	}
+	job.running = true
+	s.mu.Unlock()
+	s.wg.Go(func() {
+		run := execute(s.Ctx, job.ID, job.Task, job.Timeout, job.Retry)
+		run.Scheduled, run.Instance = scheduled, s.Instance
+		s.mu.Lock()
+		job.running = false
+		s.mu.Unlock()
+		s.recordRun(run)
+		if job.OnFinished != nil {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						log.Println("[PANIC] Recovered in job.OnFinished:", r)
+					}
+				}()
+				job.OnFinished(run)
+			}()
+		}
+		if s.OnIntervalJobFinished != nil {
+			s.OnIntervalJobFinished(job, run)
+		}
+	})
+}
+
+// jittered adds a random delay in [0, jitter) to t
+func jittered(t time.Time, jitter time.Duration) time.Time {
+	if jitter <= 0 {
+		return t
+	}
+	return t.Add(rand.N(jitter))
+}
+
+func (s *Scheduler) startOneTimeJob(job *OneTimeJob) {
+	s.wg.Go(func() {
+		if !s.claim(job) {
+			log.Printf("[INFO][JobScheduler] stored job %s not run. claimed by another instance or deleted", job.ID)
+			return
+		}
+		run := execute(s.Ctx, job.ID, job.Task, job.Timeout, job.Retry)
+		run.Scheduled, run.Instance = job.ExecTime, s.Instance
+		s.recordRun(run)
+		if job.OnFinished != nil {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						log.Println("[PANIC] Recovered in job.OnFinished:", r)
+					}
+				}()
+				job.OnFinished(run)
+			}()
+		}
+		if s.OnOneTimeJobFinished != nil {
+			s.OnOneTimeJobFinished(job, run)
+		}
+	})
+}
+
+// runTask runs task with a context derived from parent with timeout (0 = none).
+// A panic is recovered as a *PanicError
+func runTask(parent context.Context, task func(ctx context.Context) error, timeout time.Duration) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			log.Printf("[PANIC] Recovered in task: %v\n%s", r, stack)
+			err = &PanicError{Value: r, Stack: stack}
+		}
+	}()
+	ctx := parent
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, timeout)
+		defer cancel()
+	}
+	return task(ctx)
+}
+
+func (s *Scheduler) cronJobFinished(job *CronJob, run *Run) {
+	s.recordRun(run)
+	if job.OnFinished != nil {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Println("[PANIC] Recovered in job.OnFinished:", r)
+				}
+			}()
+			job.OnFinished(run)
+		}()
+	}
+	if s.OnCronJobFinished != nil {
+		s.OnCronJobFinished(job, run)
+	}
+}
+
+func (s *Scheduler) cronJobSkipped(job *CronJob, reason error) {
+	if job.OnSkipped != nil {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Println("[PANIC] Recovered in job.OnSkipped:", r)
+				}
+			}()
+			job.OnSkipped(reason)
+		}()
+	}
+	if s.OnCronJobSkipped != nil {
+		s.OnCronJobSkipped(job, reason)
+	}
+}
@@ -2,47 +2,76 @@ package schedjobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zeptools/gw-core/svc"
 )
 
 type Scheduler struct {
-	Ctx         context.Context    // Service Context
-	cancel      context.CancelFunc // Service Context CancelFunc
-	state       int                // internal service state
-	done        chan error         // Shutdown Error Channel
-	oneTimeJobs map[int64][]*OneTimeJob
-	cronJobs    map[string]*CronJob
-	mu          sync.Mutex
-	wg          sync.WaitGroup
+	Ctx          context.Context    // Service Context
+	cancel       context.CancelFunc // Service Context CancelFunc
+	state        atomic.Int32       // internal service state
+	done         chan error         // Shutdown Error Channel
+	timers       timerHeap          // one-time jobs, interval jobs and the cron tick on minute boundaries
+	wake         chan struct{}
+	cronJobs     map[string]*CronJob
+	intervalJobs map[string]*IntervalJob
+	mu           sync.Mutex
+	wg           sync.WaitGroup
+	// Location is the default location of the cron jobs added without one. nil = time.Local
+	Location *time.Location
+	// Store persists the jobs added by AddStoredJob. nil = none
+	Store        JobStore
+	Misfire      MisfirePolicy // for the stored jobs whose ExecTime passed before Start
+	MisfireGrace time.Duration // for MisfireRunWithinGrace
+	tasks        map[string]StoredTask
+	// ClusterLock elects the instance running each occurrence of the Singleton cron jobs. nil = none
+	ClusterLock ClusterLock
+	Instance    string // identifies this instance in the runs. DefaultInstance by NewScheduler
+	HistorySize int    // runs kept per job ID. DefaultHistorySize by NewScheduler
+	history     map[string][]*Run
 	// Default Callbacks
-	OnOneTimeJobAdded    func(job *OneTimeJob)
-	OnCronJobAdded       func(job *CronJob)
-	OnOneTimeJobFinished func(job *OneTimeJob, err error)
-	OnCronJobFinished    func(job *CronJob, err error)
-	OnOneTimeJobDeleted  func(job *OneTimeJob)
-	OnCronJobDeleted     func(job *CronJob)
+	OnOneTimeJobAdded     func(job *OneTimeJob)
+	OnCronJobAdded        func(job *CronJob)
+	OnOneTimeJobFinished  func(job *OneTimeJob, run *Run)
+	OnCronJobFinished     func(job *CronJob, run *Run)
+	OnIntervalJobFinished func(job *IntervalJob, run *Run)
+	OnOneTimeJobDeleted   func(job *OneTimeJob)
+	OnCronJobDeleted      func(job *CronJob)
+	OnIntervalJobDeleted  func(job *IntervalJob)
+	OnCronJobSkipped      func(job *CronJob, reason error) // a run skipped by CronJob.Overlap or run by another instance
 }
 
 func (s *Scheduler) Name() string {
 	return "JobScheduler"
 }
 
+// State returns the current service state. svc.StateREADY, svc.StateRUNNING or svc.StateSTOPPED
+func (s *Scheduler) State() int {
+	return int(s.state.Load())
+}
+
 func NewScheduler(parentCtx context.Context) *Scheduler {
 	svcCtx, svcCancel := context.WithCancel(parentCtx)
-	return &Scheduler{
-		Ctx:         svcCtx,
-		cancel:      svcCancel,
-		state:       svc.StateREADY,
-		done:        make(chan error, 1),
-		oneTimeJobs: make(map[int64][]*OneTimeJob),
-		cronJobs:    make(map[string]*CronJob),
+	s := &Scheduler{
+		Ctx:          svcCtx,
+		cancel:       svcCancel,
+		done:         make(chan error, 1),
+		wake:         make(chan struct{}, 1),
+		cronJobs:     make(map[string]*CronJob),
+		intervalJobs: make(map[string]*IntervalJob),
+		Instance:     DefaultInstance(),
+		HistorySize:  DefaultHistorySize,
+		history:      make(map[string][]*Run),
 	}
+	s.state.Store(svc.StateREADY)
+	return s
 }
 
 // UseDefaultLoggers set scheduer-level loggers with default ones
@@ -51,44 +80,67 @@ func (s *Scheduler) UseDefaultLoggers() {
 		log.Printf("[INFO] One-time job added: %s for %v", job.ID, job.ExecTime)
 	}
 	s.OnCronJobAdded = func(job *CronJob) {
-		log.Printf("[INFO] cron job added: %s", job.ID)
+		log.Printf("[INFO] cron job added: %s (%s), next run at %v", job.ID, job.Schedule(), job.NextRun(time.Now()))
+	}
+	s.OnCronJobFinished = func(job *CronJob, run *Run) {
+		if run.Err == nil {
+			log.Printf("[INFO] cron job finished: %s in %v (attempt %d)", job.ID, run.Duration(), run.Attempt)
+		} else {
+			log.Printf("[INFO] cron job finished: %s in %v (attempt %d) with error: %v", job.ID, run.Duration(), run.Attempt, run.Err)
+		}
 	}
-	s.OnCronJobFinished = func(job *CronJob, err error) {
-		if err == nil {
-			log.Printf("[INFO] cron job finished: %s", job.ID)
+	s.OnCronJobSkipped = func(job *CronJob, reason error) {
+		var claimed *ClaimedError
+		if errors.As(reason, &claimed) {
+			log.Printf("[INFO] cron job skipped: %s %v", job.ID, reason)
 		} else {
-			log.Printf("[INFO] cron job finished: %s with error: %v", job.ID, err)
+			log.Printf("[WARN] cron job skipped: %s: %v", job.ID, reason)
 		}
 	}
-	s.OnOneTimeJobFinished = func(job *OneTimeJob, err error) {
-		if err == nil {
-			log.Printf("[INFO] one-time job finished: %s", job.ID)
+	s.OnIntervalJobFinished = func(job *IntervalJob, run *Run) {
+		if run.Err != nil {
+			log.Printf("[INFO] interval job finished: %s in %v (attempt %d) with error: %v", job.ID, run.Duration(), run.Attempt, run.Err)
+		}
+	}
+	s.OnOneTimeJobFinished = func(job *OneTimeJob, run *Run) {
+		if run.Err == nil {
+			log.Printf("[INFO] one-time job finished: %s in %v (attempt %d)", job.ID, run.Duration(), run.Attempt)
 		} else {
-			log.Printf("[INFO] one-time job finished: %s with error: %v", job.ID, err)
+			log.Printf("[INFO] one-time job finished: %s in %v (attempt %d) with error: %v", job.ID, run.Duration(), run.Attempt, run.Err)
 		}
 	}
 }
 
 func (s *Scheduler) Start() error {
-	if s.state == svc.StateRUNNING {
+	if s.state.Load() == svc.StateRUNNING {
 		return fmt.Errorf("already started")
 	}
-	if s.state != svc.StateREADY {
+	if s.state.Load() != svc.StateREADY {
 		return fmt.Errorf("cannot start. not ready")
 	}
-	s.state = svc.StateRUNNING
+	var misfired []*OneTimeJob
+	if s.Store != nil {
+		var err error
+		if misfired, err = s.loadStoredJobs(); err != nil {
+			return err
+		}
+	}
+	s.state.Store(svc.StateRUNNING)
 	log.Println("[INFO][JobScheduler] service started")
+	for _, job := range misfired {
+		s.runOneTimeJob(job)
+	}
 	go s.run()
 	return nil
 }
 
 func (s *Scheduler) Stop() {
-	if s.state != svc.StateRUNNING {
+	if s.state.Load() != svc.StateRUNNING {
 		log.Println("[ERROR][JobScheduler] cannot stop. not running")
 		return
 	}
 	s.cancel()
-	s.state = svc.StateSTOPPED
+	s.state.Store(svc.StateSTOPPED)
 	log.Println("[INFO][JobScheduler] service stopped")
 }
 
@@ -97,37 +149,102 @@ func (s *Scheduler) Done() <-chan error {
 }
 
 func (s *Scheduler) run() {
-	ticker := time.NewTicker(time.Minute)
-	defer ticker.Stop()
+	s.mu.Lock()
+	s.pushTimer(&timer{at: nextMinute(time.Now())}) // the cron tick
+	s.mu.Unlock()
+	wakeup := time.NewTimer(0)
+	defer wakeup.Stop()
 	for {
+		s.mu.Lock()
+		if len(s.timers) > 0 {
+			wakeup.Reset(time.Until(s.timers[0].at))
+		}
+		s.mu.Unlock()
 		select {
 		case <-s.Ctx.Done():
 			log.Println("[INFO][Scheduler] shutting down...")
 			s.wg.Wait()   // wait for all worker goroutines
 			s.done <- nil // clean shutdown
 			return
-		case now := <-ticker.C:
+		case <-s.wake: // a timer earlier than the others added
+		case <-wakeup.C:
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
 						log.Printf("[PANIC][Scheduler] panic recovered: %v\n%s", r, debug.Stack())
 					}
 				}()
-				s.runOneTimeJobs(now)
-				s.runCronJobs(now)
+				s.fireTimers(time.Now())
 			}()
 		}
 	}
 }
 
-// GetOneTimeJobs returns a copy of all pending one-time jobs, keyed by their scheduled minute-level timestamp.
+// fireTimers runs the jobs of the due timers and reschedules the repeating ones
+func (s *Scheduler) fireTimers(now time.Time) {
+	s.mu.Lock()
+	due := s.popDueTimers(now)
+	for _, t := range due {
+		switch {
+		case t.interval != nil:
+			if _, ok := s.intervalJobs[t.interval.ID]; !ok { // deleted meanwhile
+				continue
+			}
+			job := t.interval
+			next := &timer{interval: job, base: t.base.Add(job.Every)}
+			for !next.base.After(now) { // skip the missed runs
+				next.base = next.base.Add(job.Every)
+			}
+			next.at = jittered(next.base, job.Jitter)
+			s.pushTimer(next)
+		case t.oneTime == nil: // the cron tick. the next boundary from the scheduled one, or from now if behind
+			next := t.at.Add(time.Minute)
+			if !next.After(now) {
+				next = nextMinute(now)
+			}
+			s.pushTimer(&timer{at: next})
+		}
+	}
+	s.mu.Unlock()
+	for _, t := range due {
+		switch {
+		case t.oneTime != nil:
+			s.runOneTimeJob(t.oneTime)
+		case t.interval != nil:
+			s.runIntervalJob(t.interval, t.at)
+		default:
+			s.runCronJobs(t.at)
+		}
+	}
+}
+
+// GetOneTimeJobs returns all pending one-time jobs, keyed by their ExecTime rounded up to the minute (unix minutes).
 func (s *Scheduler) GetOneTimeJobs() map[int64][]*OneTimeJob {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	result := make(map[int64][]*OneTimeJob, len(s.oneTimeJobs))
-	for key, jobs := range s.oneTimeJobs {
-		result[key] = append([]*OneTimeJob(nil), jobs...) // copy slice to avoid external mutation
+	result := make(map[int64][]*OneTimeJob)
+	for _, t := range s.timers {
+		if t.oneTime == nil {
+			continue
+		}
+		key := t.at.Unix() / 60
+		if t.at.Truncate(time.Minute) != t.at {
+			key++
+		}
+		result[key] = append(result[key], t.oneTime)
+	}
+	return result
+}
+
+// GetIntervalJobs returns a copy of all registered interval jobs, keyed by their ID.
+func (s *Scheduler) GetIntervalJobs() map[string]*IntervalJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string]*IntervalJob, len(s.intervalJobs))
+	for id, job := range s.intervalJobs {
+		result[id] = job
 	}
 	return result
 }
@@ -145,25 +262,28 @@ func (s *Scheduler) GetCronJobs() map[string]*CronJob {
 }
 
 func (s *Scheduler) AddOneTimeJob(job *OneTimeJob) error {
+	if err := s.checkExecTime(job); err != nil {
+		return err
+	}
+	s.addOneTimeJob(job)
+	return nil
+}
+
+func (s *Scheduler) checkExecTime(job *OneTimeJob) error {
 	now := time.Now()
-	margin := 30 * time.Second
-	if job.ExecTime.Before(now.Add(margin)) {
+	if job.ExecTime.Before(now) {
 		return fmt.Errorf(
-			"cannot schedule job %s too close or in the past (ExecTime: %s, now: %s)",
+			"cannot schedule job %s in the past (ExecTime: %s, now: %s)",
 			job.ID, job.ExecTime, now,
 		)
 	}
-	// Round up to the next minute if ExecTime has seconds/nanoseconds
-	regTime := job.ExecTime
-	if regTime.Second() > 0 || regTime.Nanosecond() > 0 {
-		regTime = regTime.Truncate(time.Minute).Add(time.Minute)
-	}
-	key := regTime.Unix() / 60
+	return nil
+}
+
+// addOneTimeJob schedules the job at its ExecTime in second precision (timer precision, actually)
+func (s *Scheduler) addOneTimeJob(job *OneTimeJob) {
 	s.mu.Lock()
-	if s.oneTimeJobs == nil {
-		s.oneTimeJobs = make(map[int64][]*OneTimeJob) // safety net
-	}
-	s.oneTimeJobs[key] = append(s.oneTimeJobs[key], job) // to make this safer?
+	s.pushTimer(&timer{at: job.ExecTime, oneTime: job})
 	s.mu.Unlock()
 	if job.OnAdded != nil { // Job-specific callback
 		func() {
@@ -178,7 +298,6 @@ func (s *Scheduler) AddOneTimeJob(job *OneTimeJob) error {
 	if s.OnOneTimeJobAdded != nil { // Scheduler-level default callback
 		s.OnOneTimeJobAdded(job)
 	}
-	return nil
 }
 
 func (s *Scheduler) AddCronJob(job *CronJob) error {
@@ -187,8 +306,12 @@ func (s *Scheduler) AddCronJob(job *CronJob) error {
 		s.cronJobs = make(map[string]*CronJob)
 	}
 	if _, exists := s.cronJobs[job.ID]; exists {
+		s.mu.Unlock()
 		return fmt.Errorf("cron job with ID %q already exists", job.ID)
 	}
+	if job.Location == nil && s.Location != nil {
+		job.Location = s.Location
+	}
 	s.cronJobs[job.ID] = job
 	s.mu.Unlock()
 	// Job-specific callback
@@ -209,29 +332,66 @@ func (s *Scheduler) AddCronJob(job *CronJob) error {
 	return nil
 }
 
-// DeleteOneTimeJob - Delete a job
-func (s *Scheduler) DeleteOneTimeJob(jobID string) {
+func (s *Scheduler) AddIntervalJob(job *IntervalJob) error {
+	if job.Every <= 0 {
+		return fmt.Errorf("interval job %q: Every must be positive", job.ID)
+	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	for key, jobs := range s.oneTimeJobs {
-		filtered := jobs[:0]
-		for _, job := range jobs {
-			if job.ID == jobID {
-				if s.OnOneTimeJobDeleted != nil {
-					s.OnOneTimeJobDeleted(job)
+	if _, exists := s.intervalJobs[job.ID]; exists {
+		s.mu.Unlock()
+		return fmt.Errorf("interval job with ID %q already exists", job.ID)
+	}
+	s.intervalJobs[job.ID] = job
+	base := time.Now().Add(job.InitialDelay)
+	s.pushTimer(&timer{at: jittered(base, job.Jitter), interval: job, base: base})
+	s.mu.Unlock()
+	if job.OnAdded != nil {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Println("[PANIC] Recovered in job.OnAdded:", r)
 				}
-			} else {
-				filtered = append(filtered, job)
+			}()
+			job.OnAdded()
+		}()
+	}
+	return nil
+}
+
+// DeleteOneTimeJob - Delete a job, also from the Store if stored
+func (s *Scheduler) DeleteOneTimeJob(jobID string) {
+	s.mu.Lock()
+	removed := s.removeTimers(func(t *timer) bool { return t.oneTime != nil && t.oneTime.ID == jobID })
+	s.mu.Unlock()
+	for _, t := range removed {
+		job := t.oneTime
+		if job.stored != nil {
+			if _, err := s.Store.Delete(s.Ctx, job.ID); err != nil {
+				log.Printf("[ERROR][JobScheduler] cannot delete stored job %s: %v", job.ID, err)
 			}
 		}
-		if len(filtered) == 0 {
-			delete(s.oneTimeJobs, key)
-		} else {
-			s.oneTimeJobs[key] = filtered
+		if s.OnOneTimeJobDeleted != nil {
+			s.OnOneTimeJobDeleted(job)
 		}
 	}
 }
 
+// DeleteIntervalJob removes an interval job by its ID
+func (s *Scheduler) DeleteIntervalJob(jobID string) {
+	s.mu.Lock()
+	job, exists := s.intervalJobs[jobID]
+	if !exists {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.intervalJobs, jobID)
+	s.removeTimers(func(t *timer) bool { return t.interval == job })
+	s.mu.Unlock()
+	if s.OnIntervalJobDeleted != nil {
+		s.OnIntervalJobDeleted(job)
+	}
+}
+
 // DeleteCronJob removes a cron job by its ID
 func (s *Scheduler) DeleteCronJob(jobID string) {
 	s.mu.Lock()
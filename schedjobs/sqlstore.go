@@ -0,0 +1,93 @@
+package schedjobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeptools/gw-core/db/sqldb"
+)
+
+// SQLJobStore stores the jobs in a table. exec_time is in unix milliseconds to be independent of the DB timezone
+//
+//	CREATE TABLE scheduled_jobs (
+//	  id        VARCHAR(64)  NOT NULL PRIMARY KEY,
+//	  task      VARCHAR(255) NOT NULL,
+//	  payload   TEXT         NOT NULL,
+//	  exec_time BIGINT       NOT NULL
+//	);
+type SQLJobStore struct {
+	Table  string
+	client sqldb.Client
+}
+
+// Ensure SQLJobStore implements JobStore interface
+var _ JobStore = (*SQLJobStore)(nil)
+
+func NewSQLJobStore(client sqldb.Client, table string) (*SQLJobStore, error) {
+	if !sqldb.IdentifierRegexp.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+	return &SQLJobStore{Table: table, client: client}, nil
+}
+
+// Save fails with ErrJobExists if the id is stored. The check and the insert are one statement:
+// INSERT IGNORE on mysql, ON CONFLICT DO NOTHING on pgsql.
+// On the other types, a plain INSERT fails with the primary key violation of the driver
+func (st *SQLJobStore) Save(ctx context.Context, job *StoredJob) error {
+	var query string
+	switch st.client.Conf().Type { // not importing the impls, which would link their drivers
+	case "mysql":
+		query = fmt.Sprintf("INSERT IGNORE INTO %s (id, task, payload, exec_time) VALUES (%s)", st.Table, st.client.Placeholders(4))
+	case "pgsql":
+		query = fmt.Sprintf("INSERT INTO %s (id, task, payload, exec_time) VALUES (%s) ON CONFLICT (id) DO NOTHING", st.Table, st.client.Placeholders(4))
+	default:
+		query = fmt.Sprintf("INSERT INTO %s (id, task, payload, exec_time) VALUES (%s)", st.Table, st.client.Placeholders(4))
+	}
+	result, err := st.client.Exec(ctx, query, job.ID, job.Task, string(job.Payload), job.ExecTime.UnixMilli())
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrJobExists
+	}
+	return nil
+}
+
+func (st *SQLJobStore) Delete(ctx context.Context, id string) (bool, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = %s", st.Table, st.client.SinglePlaceholder())
+	result, err := st.client.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (st *SQLJobStore) List(ctx context.Context) ([]*StoredJob, error) {
+	query := fmt.Sprintf("SELECT id, task, payload, exec_time FROM %s", st.Table)
+	rows, err := st.client.QueryRows(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var jobs []*StoredJob
+	for rows.Next() {
+		var (
+			job      StoredJob
+			payload  string
+			execTime int64
+		)
+		if err = rows.Scan(&job.ID, &job.Task, &payload, &execTime); err != nil {
+			return nil, err
+		}
+		job.Payload = []byte(payload)
+		job.ExecTime = time.UnixMilli(execTime)
+		jobs = append(jobs, &job)
+	}
+	return jobs, rows.Err()
+}
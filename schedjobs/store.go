@@ -0,0 +1,175 @@
+package schedjobs
+
+import (
+	"context"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// StoredJob is a one-time job persisted in a JobStore. Its task is looked up by name in the registered tasks
+type StoredJob struct {
+	ID       string         `json:"id"`
+	Task     string         `json:"task"` // name registered by Scheduler.RegisterTask
+	Payload  jsontext.Value `json:"payload"`
+	ExecTime time.Time      `json:"exec_time"`
+}
+
+// ErrJobExists is returned when a job with the same ID is already scheduled or stored
+var ErrJobExists = errors.New("job already exists")
+
+// JobStore persists one-time jobs so that they survive restarts
+type JobStore interface {
+	// Save adds a job. ErrJobExists if a job with the ID is stored. never overwrites
+	Save(ctx context.Context, job *StoredJob) error
+	// Delete removes a job. false if not found, e.g. claimed by another instance sharing the store
+	Delete(ctx context.Context, id string) (bool, error)
+	List(ctx context.Context) ([]*StoredJob, error)
+}
+
+// StoredTask runs a stored job with its payload
+type StoredTask func(ctx context.Context, payload jsontext.Value) error
+
+// MisfirePolicy decides what happens to a stored job whose ExecTime passed while the scheduler was not running
+type MisfirePolicy int
+
+const (
+	MisfireRunNow         MisfirePolicy = iota // run at Start
+	MisfireSkip                                // delete without running
+	MisfireRunWithinGrace                      // run at Start if late by MisfireGrace at most. delete otherwise
+)
+
+// RegisterTask registers a task for the stored jobs with the name. Register all of them before Start
+func (s *Scheduler) RegisterTask(name string, task StoredTask) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tasks == nil {
+		s.tasks = make(map[string]StoredTask)
+	}
+	s.tasks[name] = task
+}
+
+// AddStoredJob persists a one-time job running the registered task with payload marshaled as JSON at execTime,
+// and schedules it. It is reloaded from the Store at the next Start if not run yet.
+// With a Store shared by instances, the instance claiming the job first (deleting it from the Store) runs it.
+// ErrJobExists if a stored job with the id is scheduled or stored. Delete it first to replace it
+func (s *Scheduler) AddStoredJob(ctx context.Context, id string, execTime time.Time, taskName string, payload any) (*OneTimeJob, error) {
+	if s.Store == nil {
+		return nil, fmt.Errorf("no job store")
+	}
+	s.mu.Lock()
+	_, ok := s.tasks[taskName]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("task %q not registered", taskName)
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	stored := &StoredJob{ID: id, Task: taskName, Payload: payloadBytes, ExecTime: execTime}
+	job := s.storedOneTimeJob(stored)
+	if err = s.checkExecTime(job); err != nil {
+		return nil, err
+	}
+	if s.hasStoredJob(id) {
+		return nil, fmt.Errorf("stored job %q: %w", id, ErrJobExists)
+	}
+	if err = s.Store.Save(ctx, stored); err != nil {
+		if errors.Is(err, ErrJobExists) {
+			return nil, fmt.Errorf("stored job %q: %w", id, err)
+		}
+		return nil, err
+	}
+	s.addOneTimeJob(job)
+	return job, nil
+}
+
+// hasStoredJob reports whether a stored job with the id is scheduled in this instance
+func (s *Scheduler) hasStoredJob(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.timers {
+		if t.oneTime != nil && t.oneTime.stored != nil && t.oneTime.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) storedOneTimeJob(stored *StoredJob) *OneTimeJob {
+	return &OneTimeJob{
+		ID:       stored.ID,
+		ExecTime: stored.ExecTime,
+		Task: func(ctx context.Context) error {
+			s.mu.Lock()
+			task, ok := s.tasks[stored.Task]
+			s.mu.Unlock()
+			if !ok {
+				return fmt.Errorf("task %q not registered", stored.Task)
+			}
+			return task(ctx, stored.Payload)
+		},
+		stored: stored,
+	}
+}
+
+// loadStoredJobs schedules the jobs in the Store, and returns the misfired ones to run now
+// Nothing is scheduled on an error, so that a retried Start does not schedule a job twice
+func (s *Scheduler) loadStoredJobs() ([]*OneTimeJob, error) {
+	storedJobs, err := s.Store.List(s.Ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load stored jobs: %w", err)
+	}
+	now := time.Now()
+	var upcoming, misfired []*OneTimeJob
+	for _, stored := range storedJobs {
+		s.mu.Lock()
+		_, ok := s.tasks[stored.Task]
+		s.mu.Unlock()
+		if !ok {
+			// kept in the store for a release registering it
+			log.Printf("[ERROR][JobScheduler] stored job %s: task %q not registered", stored.ID, stored.Task)
+			continue
+		}
+		if s.hasStoredJob(stored.ID) { // scheduled by AddStoredJob before Start
+			continue
+		}
+		job := s.storedOneTimeJob(stored)
+		if stored.ExecTime.After(now) {
+			upcoming = append(upcoming, job)
+			continue
+		}
+		late := now.Sub(stored.ExecTime)
+		if s.Misfire == MisfireSkip || (s.Misfire == MisfireRunWithinGrace && late > s.MisfireGrace) {
+			log.Printf("[WARN][JobScheduler] misfired job %s skipped (late by %v)", stored.ID, late)
+			if _, err = s.Store.Delete(s.Ctx, stored.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		log.Printf("[INFO][JobScheduler] misfired job %s runs now (late by %v)", stored.ID, late)
+		misfired = append(misfired, job)
+	}
+	for _, job := range upcoming {
+		s.addOneTimeJob(job)
+	}
+	return misfired, nil
+}
+
+// claim deletes a stored job from the Store before running it, so that it runs once.
+// false if another instance claimed it or it was deleted
+func (s *Scheduler) claim(job *OneTimeJob) bool {
+	if job.stored == nil {
+		return true
+	}
+	claimed, err := s.Store.Delete(s.Ctx, job.ID)
+	if err != nil {
+		log.Printf("[ERROR][JobScheduler] cannot claim stored job %s: %v", job.ID, err)
+		return false
+	}
+	return claimed
+}
@@ -0,0 +1,84 @@
+package schedjobs
+
+import (
+	"container/heap"
+	"time"
+)
+
+// timer is an entry of the scheduler's timer heap. One of the jobs is set, or none for the cron tick
+type timer struct {
+	at       time.Time
+	oneTime  *OneTimeJob
+	interval *IntervalJob
+	base     time.Time // of interval. fire time without jitter, advanced by Every without drift
+	index    int       // in the heap
+}
+
+// timerHeap is a min-heap of timers by at
+type timerHeap []*timer
+
+func (h timerHeap) Len() int           { return len(h) }
+func (h timerHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x any) {
+	t := x.(*timer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *timerHeap) Pop() any {
+	old := *h
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*h = old[:n-1]
+	return t
+}
+
+// pushTimer adds a timer and wakes up the loop if it is the earliest
+// Caller must hold s.mu
+func (s *Scheduler) pushTimer(t *timer) {
+	heap.Push(&s.timers, t)
+	if t.index == 0 {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// removeTimers removes the timers matching fn and returns them
+// Caller must hold s.mu
+func (s *Scheduler) removeTimers(fn func(t *timer) bool) []*timer {
+	var removed []*timer
+	for i := 0; i < len(s.timers); {
+		if t := s.timers[i]; fn(t) {
+			heap.Remove(&s.timers, i)
+			removed = append(removed, t)
+			continue // another timer moved to i
+		}
+		i++
+	}
+	return removed
+}
+
+// popDueTimers removes and returns the timers due at now in order
+// Caller must hold s.mu
+func (s *Scheduler) popDueTimers(now time.Time) []*timer {
+	var due []*timer
+	for len(s.timers) > 0 && !s.timers[0].at.After(now) {
+		due = append(due, heap.Pop(&s.timers).(*timer))
+	}
+	return due
+}
+
+// nextMinute returns the next minute boundary after t
+func nextMinute(t time.Time) time.Time {
+	return t.Truncate(time.Minute).Add(time.Minute)
+}
@@ -0,0 +1,110 @@
+package schedjobs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zeptools/gw-core/uds"
+)
+
+// CommandGroup provides the UDS commands to inspect and control the jobs
+func (s *Scheduler) CommandGroup() *uds.CommandGroup {
+	return uds.NewCommandGroup("jobs", &jobsCommand{s: s})
+}
+
+type jobsCommand struct {
+	s *Scheduler
+}
+
+func (h *jobsCommand) Command() string {
+	return "jobs"
+}
+
+func (h *jobsCommand) Desc() string {
+	return "list, inspect and control scheduled jobs"
+}
+
+func (h *jobsCommand) Usage() string {
+	return "jobs list | jobs history <id> | jobs run <id> | jobs pause <id> | jobs resume <id>"
+}
+
+func (h *jobsCommand) HandleCommand(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: " + h.Usage())
+	}
+	sub, args := args[0], args[1:]
+	if sub == "list" {
+		return h.list(w)
+	}
+	if len(args) != 1 {
+		return errors.New("usage: " + h.Usage())
+	}
+	id := args[0]
+	switch sub {
+	case "history":
+		return h.history(w, id)
+	case "run":
+		if err := h.s.RunNow(id); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "started %s\n", id)
+	case "pause":
+		if err := h.s.PauseCronJob(id); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "paused %s\n", id)
+	case "resume":
+		if err := h.s.ResumeCronJob(id); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(w, "resumed %s\n", id)
+	default:
+		return errors.New("usage: " + h.Usage())
+	}
+	return nil
+}
+
+func (h *jobsCommand) list(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tKIND\tSCHEDULE\tSTATE\tLAST RUN\tNEXT RUN")
+	for _, info := range h.s.Jobs() {
+		state := "idle"
+		switch {
+		case info.Running:
+			state = "running"
+		case info.Paused:
+			state = "paused"
+		}
+		last := "-"
+		if info.LastRun != nil {
+			last = info.LastRun.Start.Format(time.DateTime)
+			if info.LastRun.Err != nil {
+				last += " (failed)"
+			}
+		}
+		next := "-"
+		if !info.NextRun.IsZero() {
+			next = info.NextRun.Format(time.DateTime)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", info.ID, info.Kind, info.Schedule, state, last, next)
+	}
+	return tw.Flush()
+}
+
+func (h *jobsCommand) history(w io.Writer, id string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "START\tDURATION\tATTEMPT\tINSTANCE\tERROR")
+	for _, run := range h.s.History(id) {
+		errStr := "-"
+		if run.Err != nil {
+			errStr, _, _ = strings.Cut(run.Err.Error(), "\n") // e.g. a panic with its stack
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%v\t%d\t%s\t%s\n",
+			run.Start.Format(time.DateTime), run.Duration().Round(time.Millisecond), run.Attempt, run.Instance, errStr)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,434 @@
+package svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrDrainTimeout = errors.New("svc: drain timeout")
+
+// Manager starts Services in dependency order, stops them in reverse order,
+// and restarts failed ones according to their RestartPolicy.
+// When ctx is done, all services are stopped.
+type Manager struct {
+	ctx       context.Context
+	mu        sync.Mutex
+	entries   map[string]*entry
+	order     []*entry // insertion order
+	started   []*entry // start order
+	running   int      // started entries not finished yet
+	launched  bool     // Start loop finished
+	stopping  bool
+	stopCh    chan struct{} // closed on Stop. cancels pending restarts
+	forceCh   chan struct{} // closed on Force. cuts pending StopDelays short
+	allDone   chan struct{} // closed when all started entries finished
+	errs      []error
+	stopOnce  sync.Once
+	forceOnce sync.Once
+}
+
+type entry struct {
+	name       string
+	opts       Options
+	svc        Service // current instance
+	restarts   int
+	restarting bool // exited and waiting for the restart backoff
+	finished   bool
+	err        error
+	finishedCh chan struct{}
+}
+
+func NewManager(ctx context.Context) *Manager {
+	return &Manager{
+		ctx:     ctx,
+		entries: make(map[string]*entry),
+		stopCh:  make(chan struct{}),
+		forceCh: make(chan struct{}),
+	}
+}
+
+// Add registers a service. Must be called before Start
+func (m *Manager) Add(s Service, opts Options) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name := s.Name()
+	if m.allDone != nil {
+		return fmt.Errorf("svc: cannot add %s. already started", name)
+	}
+	if _, exists := m.entries[name]; exists {
+		return fmt.Errorf("svc: service %s already added", name)
+	}
+	if opts.Restart.Mode != RestartNever && opts.Factory == nil {
+		return fmt.Errorf("svc: service %s: restart policy requires a Factory", name)
+	}
+	e := &entry{name: name, opts: opts, svc: s, finishedCh: make(chan struct{})}
+	m.entries[name] = e
+	m.order = append(m.order, e)
+	return nil
+}
+
+// Configure modifies the Options of an added service. Must be called before Start
+func (m *Manager) Configure(name string, configure func(opts *Options)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[name]
+	if !ok {
+		return fmt.Errorf("svc: unknown service %s", name)
+	}
+	if m.allDone != nil {
+		return fmt.Errorf("svc: cannot configure %s. already started", name)
+	}
+	configure(&e.opts)
+	if e.opts.Restart.Mode != RestartNever && e.opts.Factory == nil {
+		return fmt.Errorf("svc: service %s: restart policy requires a Factory", name)
+	}
+	return nil
+}
+
+// Get returns the current instance of a service. It changes when the service is restarted
+func (m *Manager) Get(name string) (Service, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[name]
+	if !ok {
+		return nil, false
+	}
+	return e.svc, true
+}
+
+// Stalled reports whether a service has stopped while the manager is neither stopping nor restarting it.
+// A draining, shutting down or restarting service is not stalled
+func (m *Manager) Stalled(name string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[name]
+	if !ok {
+		return false, fmt.Errorf("svc: unknown service %s", name)
+	}
+	if m.stopping || e.restarting {
+		return false, nil
+	}
+	return e.svc.State() == StateSTOPPED, nil
+}
+
+// Services returns the current instances in insertion order
+func (m *Manager) Services() []Service {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	services := make([]Service, len(m.order))
+	for i, e := range m.order {
+		services[i] = e.svc
+	}
+	return services
+}
+
+// Start starts all services in dependency order.
+// If a service fails to start, already started ones are stopped in reverse order.
+func (m *Manager) Start() error {
+	m.mu.Lock()
+	if m.allDone != nil {
+		m.mu.Unlock()
+		return errors.New("svc: manager already started")
+	}
+	ordered, err := m.startOrder()
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.allDone = make(chan struct{})
+	m.mu.Unlock()
+
+	for _, e := range ordered {
+		m.mu.Lock()
+		if m.stopping {
+			m.mu.Unlock()
+			break
+		}
+		s := e.svc
+		if err = e.start(s); err != nil {
+			m.mu.Unlock()
+			m.Stop()
+			return fmt.Errorf("svc: start %s: %w", e.name, err)
+		}
+		m.started = append(m.started, e)
+		m.running++
+		m.mu.Unlock()
+		log.Printf("[INFO][SVC] %s started", e.name)
+		go m.watch(e, s)
+	}
+
+	m.mu.Lock()
+	m.launched = true
+	m.closeAllDoneIfFinished()
+	m.mu.Unlock()
+
+	go func() {
+		select {
+		case <-m.ctx.Done():
+			m.Stop()
+		case <-m.allDone:
+		}
+	}()
+	return nil
+}
+
+// Wait blocks until all started services are finished and returns their errors joined
+func (m *Manager) Wait() error {
+	m.mu.Lock()
+	allDone := m.allDone
+	m.mu.Unlock()
+	if allDone == nil {
+		return errors.New("svc: manager not started")
+	}
+	<-allDone
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return errors.Join(m.errs...)
+}
+
+// Stop stops all started services in reverse start order,
+// waiting up to each service's DrainTimeout for its Done()
+// Stop while stopping forces the stop. See Force
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	again := m.stopping
+	m.mu.Unlock()
+	if again {
+		m.Force()
+	}
+	m.stopOnce.Do(func() {
+		m.mu.Lock()
+		m.stopping = true
+		close(m.stopCh)
+		started := slices.Clone(m.started)
+		m.mu.Unlock()
+
+		for i := len(started) - 1; i >= 0; i-- {
+			m.stopEntry(started[i])
+		}
+
+		m.mu.Lock()
+		m.closeAllDoneIfFinished()
+		m.mu.Unlock()
+	})
+}
+
+// Force stops the services without waiting for their StopDelays, including the one being waited. e.g. on a second shutdown signal
+// The DrainTimeouts still apply
+func (m *Manager) Force() {
+	m.forceOnce.Do(func() {
+		log.Println("[WARN][SVC] forced stop. skipping stop delays")
+		close(m.forceCh)
+	})
+}
+
+func (m *Manager) stopEntry(e *entry) {
+	m.mu.Lock()
+	s, finished := e.svc, e.finished
+	m.mu.Unlock()
+	if finished {
+		return
+	}
+	if s.State() == StateRUNNING {
+		if e.opts.StopDelay > 0 {
+			log.Printf("[INFO][SVC] stopping %s in %v ...", e.name, e.opts.StopDelay)
+			delay := time.NewTimer(e.opts.StopDelay)
+			select {
+			case <-delay.C:
+			case <-m.forceCh:
+				delay.Stop()
+			}
+		}
+		log.Printf("[INFO][SVC] stopping %s ...", e.name)
+		s.Stop()
+	}
+	timeout := e.opts.drainTimeout()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-e.finishedCh:
+	case <-timer.C:
+		log.Printf("[ERROR][SVC] %s not drained in %v", e.name, timeout)
+		m.mu.Lock()
+		if !e.finished {
+			m.finishLocked(e, fmt.Errorf("%w after %v", ErrDrainTimeout, timeout))
+		}
+		m.mu.Unlock()
+	}
+}
+
+func (m *Manager) watch(e *entry, s Service) {
+	err := <-s.Done()
+	m.handleExit(e, s, err)
+}
+
+// handleExit restarts or finishes an entry when its instance s exited
+func (m *Manager) handleExit(e *entry, s Service, err error) {
+	m.mu.Lock()
+	if e.finished || e.svc != s { // drained out already or stale instance
+		m.mu.Unlock()
+		return
+	}
+	if !m.stopping && e.shouldRestart(err) {
+		e.restarts++
+		e.restarting = true
+		n := e.restarts
+		m.mu.Unlock()
+		release(s)
+		delay := e.opts.Restart.delay(n)
+		log.Printf("[WARN][SVC] %s exited (err: %v). restart #%d in %v", e.name, err, n, delay)
+		go m.restart(e, s, delay)
+		return
+	}
+	m.finishLocked(e, err)
+	m.mu.Unlock()
+	release(s)
+}
+
+func (m *Manager) restart(e *entry, old Service, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-m.stopCh:
+		m.mu.Lock()
+		if !e.finished {
+			m.finishLocked(e, nil)
+		}
+		m.mu.Unlock()
+		return
+	}
+	s, err := e.opts.Factory()
+	if err != nil {
+		m.handleExit(e, old, fmt.Errorf("restart: %w", err))
+		return
+	}
+	m.mu.Lock()
+	if m.stopping {
+		if !e.finished {
+			m.finishLocked(e, nil)
+		}
+		m.mu.Unlock()
+		return
+	}
+	e.svc = s
+	e.restarting = false
+	err = e.start(s) // under lock so that Stop cannot miss this instance
+	m.mu.Unlock()
+	if err != nil {
+		m.handleExit(e, s, fmt.Errorf("restart: %w", err))
+		return
+	}
+	log.Printf("[INFO][SVC] %s restarted", e.name)
+	go m.watch(e, s)
+}
+
+// finishLocked marks an entry finished. An error while not stopping is fatal and stops all services
+// Caller must hold m.mu
+func (m *Manager) finishLocked(e *entry, err error) {
+	e.finished = true
+	e.restarting = false
+	e.err = err
+	close(e.finishedCh)
+	m.running--
+	if err != nil {
+		log.Printf("[ERROR][SVC] %s finished with error: %v", e.name, err)
+		m.errs = append(m.errs, fmt.Errorf("%s: %w", e.name, err))
+		if !m.stopping {
+			go m.Stop()
+		}
+	} else {
+		log.Printf("[INFO][SVC] %s finished", e.name)
+	}
+	m.closeAllDoneIfFinished()
+}
+
+// Caller must hold m.mu
+func (m *Manager) closeAllDoneIfFinished() {
+	if m.allDone == nil || m.running > 0 || !(m.launched || m.stopping) {
+		return
+	}
+	select {
+	case <-m.allDone:
+	default:
+		close(m.allDone)
+	}
+}
+
+// startOrder sorts entries topologically, keeping insertion order among independent ones
+// Caller must hold m.mu
+func (m *Manager) startOrder() ([]*entry, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[string]int, len(m.order))
+	ordered := make([]*entry, 0, len(m.order))
+	var visit func(e *entry, path []string) error
+	visit = func(e *entry, path []string) error {
+		switch marks[e.name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("svc: dependency cycle: %v", append(path, e.name))
+		}
+		marks[e.name] = visiting
+		for _, depName := range e.opts.DependsOn {
+			dep, ok := m.entries[depName]
+			if !ok {
+				return fmt.Errorf("svc: %s depends on unknown service %s", e.name, depName)
+			}
+			if err := visit(dep, append(path, e.name)); err != nil {
+				return err
+			}
+		}
+		marks[e.name] = visited
+		ordered = append(ordered, e)
+		return nil
+	}
+	for _, e := range m.order {
+		if err := visit(e, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// start starts an instance, passing it the DrainTimeout if it bounds its own shutdown
+func (e *entry) start(s Service) error {
+	if d, ok := s.(DrainTimeoutSetter); ok {
+		d.SetDrainTimeout(e.opts.drainTimeout())
+	}
+	return s.Start()
+}
+
+func (e *entry) shouldRestart(err error) bool {
+	p := e.opts.Restart
+	if e.opts.Factory == nil {
+		return false
+	}
+	if p.MaxRestarts > 0 && e.restarts >= p.MaxRestarts {
+		return false
+	}
+	switch p.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnError:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// release stops an exited instance which still reports running to free its resources
+func release(s Service) {
+	if s.State() == StateRUNNING {
+		s.Stop()
+	}
+}
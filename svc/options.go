@@ -0,0 +1,58 @@
+package svc
+
+import "time"
+
+const (
+	DefaultDrainTimeout = 15 * time.Second
+	DefaultBackoff      = time.Second
+	DefaultMaxBackoff   = time.Minute
+)
+
+type RestartMode int
+
+const (
+	RestartNever   RestartMode = iota // default
+	RestartOnError                    // restart when Done() delivers an error
+	RestartAlways                     // restart whenever the service exits unless the Manager is stopping
+)
+
+type RestartPolicy struct {
+	Mode        RestartMode
+	MaxRestarts int           // 0 = unlimited
+	Backoff     time.Duration // delay before the 1st restart, doubled for each consecutive restart. 0 = DefaultBackoff
+	MaxBackoff  time.Duration // 0 = DefaultMaxBackoff
+}
+
+// delay returns the backoff before the n'th restart (n >= 1)
+func (p *RestartPolicy) delay(n int) time.Duration {
+	d := p.Backoff
+	if d <= 0 {
+		d = DefaultBackoff
+	}
+	maxD := p.MaxBackoff
+	if maxD <= 0 {
+		maxD = DefaultMaxBackoff
+	}
+	for i := 1; i < n && d < maxD; i++ {
+		d *= 2
+	}
+	return min(d, maxD)
+}
+
+// Options for a Service managed by Manager
+type Options struct {
+	DependsOn    []string      // Names of services which must be started before and stopped after this one
+	DrainTimeout time.Duration // Max wait for Done() after Stop(). 0 = DefaultDrainTimeout
+	StopDelay    time.Duration // Wait before Stop(). e.g. to let load balancers observe a failing readiness
+	Restart      RestartPolicy
+	// Factory builds a fresh READY instance for a restart. A stopped Service cannot be started again.
+	// Required unless Restart.Mode is RestartNever
+	Factory func() (Service, error)
+}
+
+func (o *Options) drainTimeout() time.Duration {
+	if o.DrainTimeout <= 0 {
+		return DefaultDrainTimeout
+	}
+	return o.DrainTimeout
+}
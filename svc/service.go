@@ -1,5 +1,7 @@
 package svc
 
+import "time"
+
 type Service interface {
 	Start() error // bootstrapping error only
 	Stop()
@@ -7,4 +9,11 @@ type Service interface {
 	// Since consumed by framework.Core only, Do Not Close the channel in a method
 	Done() <-chan error
 	Name() string
+	State() int // StateREADY, StateRUNNING or StateSTOPPED
+}
+
+// DrainTimeoutSetter is implemented by a Service which bounds its own graceful shutdown.
+// Manager sets Options.DrainTimeout before starting each instance, so that the Service reports Done within it
+type DrainTimeoutSetter interface {
+	SetDrainTimeout(timeout time.Duration)
 }
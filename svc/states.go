@@ -5,3 +5,17 @@ const (
 	StateRUNNING = 2
 	StateSTOPPED = 3
 )
+
+// StateName returns a display name of a service state
+func StateName(state int) string {
+	switch state {
+	case StateREADY:
+		return "ready"
+	case StateRUNNING:
+		return "running"
+	case StateSTOPPED:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
@@ -1,40 +1,153 @@
 package throttle
 
 import (
+	"context"
 	"sync"
 	"time"
 )
 
 type Bucket[K comparable] struct {
 	mu          sync.Mutex // protects access to bucket state
-	tokens      int
+	tokens      int        // negative while tokens are reserved in advance
 	lastCheck   time.Time
 	parentGroup *BucketGroup[K] // back-reference to its parentGroup group
 }
 
-// refill tokens
-// Since this modifies the bucket's state, this should be wrapped by mutex lock/unlock
-func (b *Bucket[K]) refill(now time.Time) {
-	conf := b.parentGroup.conf
-	elapsed := now.Sub(b.lastCheck)
+// Result is the outcome of taking tokens from a bucket
+type Result struct {
+	Allowed    bool
+	Limit      int           // Burst of the bucket
+	Remaining  int           // tokens left after the check
+	RetryAfter time.Duration // until the tokens are available. 0 if Allowed
+	ResetAfter time.Duration // until the bucket is full again
+}
+
+// refilled returns the tokens and lastCheck refilled until now, without modifying the bucket
+// Caller must hold b.mu
+func (b *Bucket[K]) refilled(now time.Time) (int, time.Time) {
+	conf := b.parentGroup.Conf()
+	tokens, lastCheck := b.tokens, b.lastCheck
+	elapsed := now.Sub(lastCheck)
 	if elapsed >= conf.IncrPeriod { // compare
 		times := int(elapsed / conf.IncrPeriod) // division
-		b.tokens += times * conf.Increment
-		if b.tokens > conf.Burst {
-			b.tokens = conf.Burst
+		tokens += times * conf.Increment
+		if tokens > conf.Burst {
+			tokens = conf.Burst
 		}
-		b.lastCheck = b.lastCheck.Add(time.Duration(times) * conf.IncrPeriod)
+		lastCheck = lastCheck.Add(time.Duration(times) * conf.IncrPeriod)
 	}
+	return tokens, lastCheck
+}
+
+// refill tokens
+// Since this modifies the bucket's state, this should be wrapped by mutex lock/unlock
+func (b *Bucket[K]) refill(now time.Time) {
+	b.tokens, b.lastCheck = b.refilled(now)
 }
 
 func (b *Bucket[K]) Allow(now time.Time) bool {
+	return b.AllowN(now, 1)
+}
+
+// AllowN consumes n tokens at once if available. e.g. a bulk request costs 10
+func (b *Bucket[K]) AllowN(now time.Time, n int) bool {
+	return b.TakeN(now, n).Allowed
+}
+
+// Take consumes a token like Allow, and reports the state of the bucket for the response headers
+func (b *Bucket[K]) Take(now time.Time) Result {
+	return b.TakeN(now, 1)
+}
+
+// TakeN consumes n tokens like AllowN, and reports the state of the bucket
+func (b *Bucket[K]) TakeN(now time.Time, n int) Result {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	allowed := n >= 0 && b.tokens >= n
+	if allowed {
+		b.tokens -= n
+	}
+	return b.result(allowed, n, now)
+}
+
+// Peek reports the state of the bucket without consuming: Allowed if a token is available
+func (b *Bucket[K]) Peek(now time.Time) Result {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tokens, lastCheck := b.refilled(now)
+	return resultOf(b.parentGroup.Conf(), tokens, lastCheck, tokens > 0, 1, now)
+}
+
+// Reserve consumes n tokens now, even before they are available if it is within maxDelay.
+// The tokens can be used at Reservation.At. Not OK if n is over Burst or not available within maxDelay
+func (b *Bucket[K]) Reserve(now time.Time, n int, maxDelay time.Duration) *Reservation {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	b.refill(now)
-	if b.tokens <= 0 {
-		return false
+	conf := b.parentGroup.Conf()
+	if n < 0 || n > conf.Burst {
+		return &Reservation{}
+	}
+	at := now
+	if b.tokens < n {
+		at = b.lastCheck.Add(untilTokens(conf, b.tokens, n))
+	}
+	if at.Sub(now) > maxDelay {
+		return &Reservation{At: at}
+	}
+	b.tokens -= n
+	return &Reservation{OK: true, At: at, cancel: func() { b.giveBack(n) }}
+}
+
+// Wait blocks until n tokens are consumed or ctx is done
+func (b *Bucket[K]) Wait(ctx context.Context, n int) error {
+	if n > b.parentGroup.Conf().Burst {
+		return ErrExceedsBurst
+	}
+	return wait(ctx, func(maxDelay time.Duration) *Reservation {
+		return b.Reserve(time.Now(), n, maxDelay)
+	})
+}
+
+// giveBack returns the tokens of a cancelled reservation
+func (b *Bucket[K]) giveBack(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	b.tokens = min(b.tokens+n, b.parentGroup.Conf().Burst)
+}
+
+// result reports the state after a check of n tokens
+// Caller must hold b.mu
+func (b *Bucket[K]) result(allowed bool, n int, now time.Time) Result {
+	return resultOf(b.parentGroup.Conf(), b.tokens, b.lastCheck, allowed, n, now)
+}
+
+func resultOf(conf *BucketConf, tokens int, lastCheck time.Time, allowed bool, n int, now time.Time) Result {
+	r := Result{
+		Allowed:   allowed,
+		Limit:     conf.Burst,
+		Remaining: min(max(tokens, 0), conf.Burst), // Burst may have been lowered on hot reload
+	}
+	if !allowed {
+		r.RetryAfter = lastCheck.Add(untilTokens(conf, tokens, n)).Sub(now)
+	}
+	if tokens < conf.Burst {
+		r.ResetAfter = lastCheck.Add(untilTokens(conf, tokens, conf.Burst)).Sub(now)
+	}
+	return r
+}
+
+// untilTokens is the duration from lastCheck until tokens are refilled to target
+func untilTokens(conf *BucketConf, tokens int, target int) time.Duration {
+	if tokens >= target {
+		return 0
 	}
-	b.tokens--
-	return true
+	periods := (target - tokens + conf.Increment - 1) / conf.Increment
+	return time.Duration(periods) * conf.IncrPeriod
 }
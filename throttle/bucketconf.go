@@ -1,9 +1,26 @@
 package throttle
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type BucketConf struct {
-	Burst      int           // maximum number of tokens in the bucket
-	Increment  int           // how many tokens to add each period
-	IncrPeriod time.Duration // how often to add Increment
+	Burst      int           `json:"burst"`                    // maximum number of tokens in the bucket
+	Increment  int           `json:"increment"`                // how many tokens to add each period
+	IncrPeriod time.Duration `json:"incr_period,format:units"` // how often to add Increment. e.g. "1s", "500ms"
+}
+
+// Validate checks the conf before it is applied to a BucketGroup
+func (c *BucketConf) Validate() error {
+	if c.Burst <= 0 {
+		return fmt.Errorf("burst must be positive: %d", c.Burst)
+	}
+	if c.Increment <= 0 {
+		return fmt.Errorf("increment must be positive: %d", c.Increment)
+	}
+	if c.IncrPeriod <= 0 {
+		return fmt.Errorf("incr_period must be positive: %v", c.IncrPeriod)
+	}
+	return nil
 }
@@ -2,12 +2,18 @@ package throttle
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type BucketGroup[K comparable] struct {
-	conf    *BucketConf
-	buckets *sync.Map // K -> *Bucket[K]
+	conf    atomic.Pointer[BucketConf] // [Hot Reload] swapped by BucketStore.SetBucketGroup
+	buckets *sync.Map                  // K -> *Bucket[K]
+}
+
+// Conf returns the current BucketConf of the group
+func (g *BucketGroup[K]) Conf() *BucketConf {
+	return g.conf.Load()
 }
 
 func (g *BucketGroup[K]) GetBucket(id K) (*Bucket[K], bool) {
@@ -18,10 +24,26 @@ func (g *BucketGroup[K]) GetBucket(id K) (*Bucket[K], bool) {
 	return bAny.(*Bucket[K]), true
 }
 
-func (g *BucketGroup[K]) SetBucket(id K, tokens int, now time.Time) {
-	g.buckets.Store(id, &Bucket[K]{
+// SetBucket stores a new bucket with tokens, replacing the existing one, and returns it
+func (g *BucketGroup[K]) SetBucket(id K, tokens int, now time.Time) *Bucket[K] {
+	b := &Bucket[K]{
 		tokens:      tokens,
 		lastCheck:   now,
 		parentGroup: g,
+	}
+	g.buckets.Store(id, b)
+	return b
+}
+
+// bucket returns the bucket of id, adding a full one if not found
+func (g *BucketGroup[K]) bucket(id K, now time.Time) *Bucket[K] {
+	if b, ok := g.GetBucket(id); ok {
+		return b
+	}
+	bAny, _ := g.buckets.LoadOrStore(id, &Bucket[K]{
+		tokens:      g.Conf().Burst,
+		lastCheck:   now,
+		parentGroup: g,
 	})
+	return bAny.(*Bucket[K])
 }
@@ -2,9 +2,11 @@ package throttle
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zeptools/gw-core/svc"
@@ -13,51 +15,60 @@ import (
 type BucketStore[K comparable] struct {
 	Ctx              context.Context    // Service Context
 	cancel           context.CancelFunc // Service Context CancelFunc
-	state            int                // internal service state
+	state            atomic.Int32       // internal service state
 	done             chan error         // Shutdown Error Channel
 	cleanupCycle     time.Duration
 	cleanupOlderThan time.Duration
 	groups           map[string]*BucketGroup[K]
+	groupsMu         sync.RWMutex // protects groups map. BucketGroups can be added at runtime on hot reload
+
+	KV *KVBackend // [Optional] buckets shared across instances. nil = local buckets only. set before Start
 }
 
 func (s *BucketStore[K]) Name() string {
 	return "ThrottleBucketStore"
 }
 
+// State returns the current service state. svc.StateREADY, svc.StateRUNNING or svc.StateSTOPPED
+func (s *BucketStore[K]) State() int {
+	return int(s.state.Load())
+}
+
 func NewBucketStore[K comparable](parentCtx context.Context, cleanupCycle time.Duration, cleanupOlderThan time.Duration) *BucketStore[K] {
 	svcCtx, svcCancel := context.WithCancel(parentCtx)
-	return &BucketStore[K]{
+	s := &BucketStore[K]{
 		Ctx:              svcCtx,
 		cancel:           svcCancel,
-		state:            svc.StateREADY,
 		done:             make(chan error, 1),
 		cleanupCycle:     cleanupCycle,
 		cleanupOlderThan: cleanupOlderThan,
 		groups:           make(map[string]*BucketGroup[K]),
 	}
+	s.state.Store(svc.StateREADY)
+	return s
 }
 
 // Start starts a service that manages buckets
 func (s *BucketStore[K]) Start() error {
-	if s.state == svc.StateRUNNING {
+	if s.state.Load() == svc.StateRUNNING {
 		return fmt.Errorf("already started")
 	}
-	if s.state != svc.StateREADY {
+	if s.state.Load() != svc.StateREADY {
 		return fmt.Errorf("cannot start. not ready")
 	}
-	s.state = svc.StateRUNNING
+	s.state.Store(svc.StateRUNNING)
 	log.Printf("[INFO][Throttle] cleanup service started cycle=%v exp=%v", s.cleanupCycle, s.cleanupOlderThan)
 	go s.run()
 	return nil
 }
 
 func (s *BucketStore[K]) Stop() {
-	if s.state != svc.StateRUNNING {
+	if s.state.Load() != svc.StateRUNNING {
 		log.Println("[ERROR][Throttle] cannot stop. not running")
 		return
 	}
 	s.cancel()
-	s.state = svc.StateSTOPPED
+	s.state.Store(svc.StateSTOPPED)
 	log.Println("[INFO][Throttle] service stopped")
 }
 
@@ -89,37 +100,175 @@ func (s *BucketStore[K]) run() {
 }
 
 func (s *BucketStore[K]) GetBucketGroup(id string) (*BucketGroup[K], bool) {
+	s.groupsMu.RLock()
+	defer s.groupsMu.RUnlock()
 	g, ok := s.groups[id]
 	return g, ok
 }
 
 func (s *BucketStore[K]) GetBucket(groupID string, localBucketID K) (*Bucket[K], bool) {
-	g, ok := s.groups[groupID]
+	g, ok := s.GetBucketGroup(groupID)
 	if !ok {
 		return nil, false
 	}
 	return g.GetBucket(localBucketID)
 }
 
+// SetBucketGroup adds a BucketGroup or swaps the conf of an existing one.
+// Existing buckets are kept and refilled by the new conf from their next check.
 func (s *BucketStore[K]) SetBucketGroup(id string, conf *BucketConf) {
-	s.groups[id] = &BucketGroup[K]{
-		conf:    conf,
+	s.groupsMu.Lock()
+	defer s.groupsMu.Unlock()
+	if g, exists := s.groups[id]; exists {
+		g.conf.Store(conf)
+		return
+	}
+	g := &BucketGroup[K]{
 		buckets: &sync.Map{},
 	}
+	g.conf.Store(conf)
+	s.groups[id] = g
+}
+
+// bucketGroups returns a snapshot of the BucketGroups for iteration without holding the lock
+func (s *BucketStore[K]) bucketGroups() map[string]*BucketGroup[K] {
+	s.groupsMu.RLock()
+	defer s.groupsMu.RUnlock()
+	groups := make(map[string]*BucketGroup[K], len(s.groups))
+	for id, g := range s.groups {
+		groups[id] = g
+	}
+	return groups
 }
 
 func (s *BucketStore[K]) Allow(groupID string, localBucketID K, now time.Time) bool {
+	return s.AllowN(groupID, localBucketID, 1, now)
+}
+
+// AllowN consumes n tokens at once if available. e.g. a bulk request costs 10
+func (s *BucketStore[K]) AllowN(groupID string, localBucketID K, n int, now time.Time) bool {
+	r, err := s.TakeN(groupID, localBucketID, n, now)
+	return err == nil && r.Allowed
+}
+
+// Take consumes a token like Allow, and reports the state of the bucket.
+// ErrGroupNotFound if groupID is not found, and the request is always blocked like Allow
+// With KV, the shared bucket is used unless the kvdb is unavailable. An error of the kvdb rejecting the check is returned
+func (s *BucketStore[K]) Take(groupID string, localBucketID K, now time.Time) (Result, error) {
+	return s.TakeN(groupID, localBucketID, 1, now)
+}
+
+// TakeN consumes n tokens like AllowN, and reports the state of the bucket
+// ErrExceedsBurst if n is negative or over Burst, which would never be allowed
+func (s *BucketStore[K]) TakeN(groupID string, localBucketID K, n int, now time.Time) (Result, error) {
 	g, ok := s.GetBucketGroup(groupID)
 	if !ok {
-		return false // Invalid groupID always Blocked
+		return Result{}, ErrGroupNotFound // Invalid groupID always Blocked
+	}
+	conf := g.Conf()
+	if n < 0 || n > conf.Burst {
+		return Result{Limit: conf.Burst}, fmt.Errorf("%w: %d tokens of burst %d", ErrExceedsBurst, n, conf.Burst)
+	}
+	if r, ok, err := s.takeShared(groupID, localBucketID, conf, n, 0); ok {
+		return r, err
+	}
+	return g.bucket(localBucketID, now).TakeN(now, n), nil
+}
+
+// Peek reports the state of the bucket without consuming, e.g. for the response headers.
+// Allowed if a token is available. ErrGroupNotFound if groupID is not found
+func (s *BucketStore[K]) Peek(groupID string, localBucketID K, now time.Time) (Result, error) {
+	g, ok := s.GetBucketGroup(groupID)
+	if !ok {
+		return Result{}, ErrGroupNotFound
+	}
+	if r, ok, err := s.takeShared(groupID, localBucketID, g.Conf(), 0, 0); ok {
+		r.Allowed = r.Remaining > 0
+		return r, err
 	}
 	b, ok := g.GetBucket(localBucketID)
-	if ok {
-		return b.Allow(now)
+	if !ok { // a fresh bucket is full
+		return Result{Allowed: true, Limit: g.Conf().Burst, Remaining: g.Conf().Burst}, nil
+	}
+	return b.Peek(now), nil
+}
+
+// Reserve consumes n tokens now, even before they are available if it is within maxDelay.
+// See Bucket.Reserve. ErrGroupNotFound if groupID is not found
+func (s *BucketStore[K]) Reserve(groupID string, localBucketID K, n int, maxDelay time.Duration, now time.Time) (*Reservation, error) {
+	g, ok := s.GetBucketGroup(groupID)
+	if !ok {
+		return nil, ErrGroupNotFound
+	}
+	conf := g.Conf()
+	if n < 0 || n > conf.Burst {
+		return &Reservation{}, nil
+	}
+	maxDelay = max(maxDelay, 0)
+	if r, ok, err := s.takeShared(groupID, localBucketID, conf, n, maxDelay); ok {
+		if err != nil {
+			return nil, err
+		}
+		at := now.Add(r.RetryAfter)
+		if !r.Allowed {
+			return &Reservation{At: at}, nil
+		}
+		return &Reservation{OK: true, At: at, cancel: func() {
+			s.takeShared(groupID, localBucketID, conf, -n, 0)
+		}}, nil
+	}
+	return g.bucket(localBucketID, now).Reserve(now, n, maxDelay), nil
+}
+
+// Wait blocks until n tokens are consumed or ctx is done
+func (s *BucketStore[K]) Wait(ctx context.Context, groupID string, localBucketID K, n int) error {
+	g, ok := s.GetBucketGroup(groupID)
+	if !ok {
+		return ErrGroupNotFound
+	}
+	if n > g.Conf().Burst {
+		return ErrExceedsBurst
+	}
+	var reserveErr error
+	err := wait(ctx, func(maxDelay time.Duration) *Reservation {
+		r, err := s.Reserve(groupID, localBucketID, n, maxDelay, time.Now())
+		if err != nil {
+			reserveErr = err
+			return &Reservation{}
+		}
+		return r
+	})
+	if reserveErr != nil {
+		return reserveErr
+	}
+	return err
+}
+
+// giveBack returns n tokens taken by TakeN. e.g. the request was rejected by another Limit
+func (s *BucketStore[K]) giveBack(groupID string, localBucketID K, n int) {
+	g, ok := s.GetBucketGroup(groupID)
+	if !ok {
+		return
+	}
+	if _, ok, _ = s.takeShared(groupID, localBucketID, g.Conf(), -n, 0); ok {
+		return
+	}
+	if b, ok := g.GetBucket(localBucketID); ok {
+		b.giveBack(n)
+	}
+}
+
+// takeShared takes n tokens of the shared bucket with KV. false = use the local bucket, i.e. no KV or the kvdb is unavailable
+// now of the local buckets is not passed. the shared ones are on the clock of the kvdb server
+func (s *BucketStore[K]) takeShared(groupID string, localBucketID K, conf *BucketConf, n int, maxDelay time.Duration) (Result, bool, error) {
+	if s.KV == nil || !s.KV.available() {
+		return Result{}, false, nil
+	}
+	r, err := s.KV.take(context.Background(), groupID, localBucketID, conf, n, maxDelay)
+	if errors.Is(err, errKVUnavailable) {
+		return Result{}, false, nil // fall back to the local bucket
 	}
-	// consume 1 token from the fresh bucket
-	g.SetBucket(localBucketID, g.conf.Burst-1, now)
-	return true
+	return r, true, err
 }
 
 // Inspect returns a snapshot of all BucketGroup IDs and their local Bucket IDs.
@@ -128,7 +277,7 @@ func (s *BucketStore[K]) Allow(groupID string, localBucketID K, now time.Time) b
 func (s *BucketStore[K]) Inspect() map[string][]K {
 	result := make(map[string][]K)
 
-	for groupID, bucketGroup := range s.groups {
+	for groupID, bucketGroup := range s.bucketGroups() {
 		var ids []K
 		bucketGroup.buckets.Range(func(localID, _ any) bool {
 			ids = append(ids, localID.(K))
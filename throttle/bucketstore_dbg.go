@@ -10,7 +10,7 @@ import (
 func (s *BucketStore[K]) Cleanup(now time.Time) {
 	log.Printf("[DEBUG][Throttle] cleaning Buckets older than %v", s.cleanupOlderThan)
 	cleanCnt := 0
-	for gid, g := range s.groups {
+	for gid, g := range s.bucketGroups() {
 		log.Printf("[DEBUG][Throttle] cleaning BucketGroup %q", gid)
 		g.buckets.Range(func(id, value any) bool {
 			b := value.(*Bucket[K])
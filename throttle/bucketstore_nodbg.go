@@ -7,7 +7,7 @@ import (
 )
 
 func (s *BucketStore[K]) Cleanup(now time.Time) {
-	for _, g := range s.groups {
+	for _, g := range s.bucketGroups() {
 		g.buckets.Range(func(id, value any) bool {
 			b := value.(*Bucket[K])
 			// lock per bucket while checking/removing
@@ -0,0 +1,104 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeptools/gw-core/db/kvdb"
+)
+
+const (
+	// DefaultKVTimeout bounds a kvdb round trip of a check, so that a slow kvdb does not stall the requests
+	DefaultKVTimeout = 100 * time.Millisecond
+	// DefaultKVCooldown is how long the local buckets are used after a kvdb failure before trying the kvdb again
+	DefaultKVCooldown = 5 * time.Second
+)
+
+// KVBackend keeps the buckets in a kvdb shared by the instances, so that a client gets the configured Burst
+// across all of them instead of per instance. Each check is evaluated atomically in one round trip (kvdb.Client.Throttle).
+// While the kvdb is unavailable, the BucketStore falls back to its local buckets.
+//
+// The bucket is evaluated by GCRA on the clock of the kvdb server: a token is added every IncrPeriod/Increment,
+// which is smoother than the local buckets adding Increment tokens at once every IncrPeriod
+type KVBackend struct {
+	Namespace string        // key prefix. e.g. Core.AppName + "_throttle"
+	Timeout   time.Duration // per check. 0 = DefaultKVTimeout
+	Cooldown  time.Duration // local fallback after a failure. 0 = DefaultKVCooldown
+
+	client    kvdb.Client
+	downUntil atomic.Int64 // unix nano until which the local buckets are used. 0 = kvdb is up
+}
+
+func NewKVBackend(client kvdb.Client, namespace string) *KVBackend {
+	return &KVBackend{
+		Namespace: namespace,
+		Timeout:   DefaultKVTimeout,
+		Cooldown:  DefaultKVCooldown,
+		client:    client,
+	}
+}
+
+func (b *KVBackend) bucketKey(groupID string, localBucketID any) string {
+	return fmt.Sprintf("%s:%s:%v", b.Namespace, groupID, localBucketID)
+}
+
+// available reports whether the kvdb is worth trying. false while cooling down after a failure
+func (b *KVBackend) available() bool {
+	until := b.downUntil.Load()
+	return until == 0 || time.Now().UnixNano() >= until
+}
+
+// errKVUnavailable wraps the errors of take after which the local buckets are used
+var errKVUnavailable = errors.New("throttle: kvdb unavailable")
+
+// rejected reports whether err is the kvdb rejecting the command, e.g. an invalid BucketConf or a key of another type,
+// rather than a failure to reach it (transport errors, timeouts, a closed client)
+func rejected(err error) bool {
+	return errors.Is(err, kvdb.ErrInvalidArgument) || errors.Is(err, kvdb.ErrWrongType) ||
+		errors.Is(err, kvdb.ErrNotInteger) || errors.Is(err, kvdb.ErrNotSupported)
+}
+
+// take consumes n tokens of the shared bucket, reserving them within maxDelay. 0 = peek, negative = give back
+// A failure to reach the kvdb starts the cooldown and is wrapped with errKVUnavailable. A rejected command is returned as is
+func (b *KVBackend) take(ctx context.Context, groupID string, localBucketID any, conf *BucketConf, n int, maxDelay time.Duration) (Result, error) {
+	timeout := b.Timeout
+	if timeout <= 0 {
+		timeout = DefaultKVTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	emission := max(conf.IncrPeriod/time.Duration(conf.Increment), time.Microsecond) // kvdb servers count in microseconds
+	tr, err := b.client.Throttle(ctx, b.bucketKey(groupID, localBucketID), int64(conf.Burst), emission, int64(n), maxDelay)
+	if err != nil {
+		if rejected(err) { // the kvdb is up. falling back would hide the error
+			return Result{}, fmt.Errorf("throttle: shared bucket %s: %w", b.bucketKey(groupID, localBucketID), err)
+		}
+		b.fail(err)
+		return Result{}, fmt.Errorf("%w: %w", errKVUnavailable, err)
+	}
+	if b.downUntil.Swap(0) != 0 {
+		log.Println("[INFO][Throttle] kvdb recovered. shared buckets resumed")
+	}
+	return Result{
+		Allowed:    tr.Allowed,
+		Limit:      conf.Burst,
+		Remaining:  int(tr.Remaining),
+		RetryAfter: tr.RetryAfter,
+		ResetAfter: tr.ResetAfter,
+	}, nil
+}
+
+// fail starts the cooldown. Logs once per outage
+func (b *KVBackend) fail(err error) {
+	cooldown := b.Cooldown
+	if cooldown <= 0 {
+		cooldown = DefaultKVCooldown
+	}
+	if b.downUntil.Swap(time.Now().Add(cooldown).UnixNano()) == 0 {
+		log.Printf("[WARN][Throttle] kvdb unavailable. falling back to local buckets for %v: %v", cooldown, err)
+	}
+}
@@ -0,0 +1,184 @@
+package throttle
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zeptools/gw-core/clients"
+	"github.com/zeptools/gw-core/requests"
+	"github.com/zeptools/gw-core/responses"
+	"github.com/zeptools/gw-core/routing"
+	"github.com/zeptools/gw-core/web/session"
+)
+
+// KeyFunc derives the bucket ID of a request. false = the Limit does not apply to the request
+type KeyFunc[K comparable] func(r *http.Request) (K, bool)
+
+// Limit applies the BucketGroup to requests, one bucket per key
+type Limit[K comparable] struct {
+	Group string     // BucketGroup ID in the BucketStore
+	Key   KeyFunc[K] // e.g. KeyByClientIP
+	Cost  int        // tokens per request. e.g. 10 for a bulk endpoint. 0 = 1
+}
+
+// Limiter is a routing.HandlerWrapper which rejects the requests over any of its Limits
+// with 429 Too Many Requests and Retry-After.
+// Limits are checked in order, and the first one rejecting stops the check. e.g. per-IP then per-user
+// The tokens taken by the earlier Limits are given back on rejection, so that composed Limits do not drain each other
+// The IETF RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers report the most restrictive Limit
+type Limiter[K comparable] struct {
+	Store  *BucketStore[K]
+	Limits []Limit[K]
+}
+
+// Ensure Limiter implements routing.HandlerWrapper
+var _ routing.HandlerWrapper = (*Limiter[string])(nil)
+
+// NewLimiter checks that the BucketGroups of the limits are set in store and can hold their Cost,
+// so that a misconfigured route fails at setup instead of blocking every request
+func NewLimiter[K comparable](store *BucketStore[K], limits ...Limit[K]) (*Limiter[K], error) {
+	for _, limit := range limits {
+		g, ok := store.GetBucketGroup(limit.Group)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrGroupNotFound, limit.Group)
+		}
+		if cost, burst := max(limit.Cost, 1), g.Conf().Burst; cost > burst {
+			return nil, fmt.Errorf("%w: cost %d of bucket group %q with burst %d", ErrExceedsBurst, cost, limit.Group, burst)
+		}
+	}
+	return &Limiter[K]{Store: store, Limits: limits}, nil
+}
+
+// Wrap rejects the request before reaching inner if any of the Limits is exceeded
+func (l *Limiter[K]) Wrap(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			tightest Result
+			taken    []takenTokens[K]
+		)
+		reject := func() {
+			for _, t := range taken {
+				l.Store.giveBack(t.group, t.key, t.n)
+			}
+			responses.WriteSimpleErrorJSON(w, http.StatusTooManyRequests, "too many requests")
+		}
+		now := time.Now()
+		for _, limit := range l.Limits {
+			key, ok := limit.Key(r)
+			if !ok {
+				continue
+			}
+			cost := max(limit.Cost, 1)
+			res, err := l.Store.TakeN(limit.Group, key, cost, now)
+			if err != nil {
+				log.Printf("[ERROR][Throttle] bucket group %q: %v. blocking %s %s", limit.Group, err, r.Method, r.URL.Path)
+				reject()
+				return
+			}
+			if !res.Allowed {
+				setRateLimitHeaders(w.Header(), res)
+				w.Header().Set("Retry-After", ceilSeconds(res.RetryAfter))
+				reject()
+				return
+			}
+			if len(taken) == 0 || res.Remaining < tightest.Remaining {
+				tightest = res
+			}
+			taken = append(taken, takenTokens[K]{group: limit.Group, key: key, n: cost})
+		}
+		if len(taken) > 0 {
+			setRateLimitHeaders(w.Header(), tightest)
+		}
+		inner.ServeHTTP(w, r)
+	})
+}
+
+// takenTokens records a TakeN to be given back when a later Limit rejects the request
+type takenTokens[K comparable] struct {
+	group string
+	key   K
+	n     int
+}
+
+func setRateLimitHeaders(h http.Header, res Result) {
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", ceilSeconds(res.ResetAfter))
+}
+
+// ceilSeconds formats d in delta-seconds rounded up, so that retrying after it is not too early
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(max(d, 0).Seconds())), 10)
+}
+
+// Key Extractors
+// They key by string. For a BucketStore keyed by another type, adapt them with KeyAs
+
+// KeyAs adapts a string KeyFunc to a BucketStore keyed by K with conv. e.g. KeyAs(KeyByClientIP, HashKey) for int64
+func KeyAs[K comparable](key KeyFunc[string], conv func(string) K) KeyFunc[K] {
+	return func(r *http.Request) (K, bool) {
+		s, ok := key(r)
+		if !ok {
+			var zero K
+			return zero, false
+		}
+		return conv(s), true
+	}
+}
+
+// HashKey hashes a string key into an int64 bucket ID by FNV-1a. Collisions are negligible for rate limiting
+func HashKey(s string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return int64(h.Sum64())
+}
+
+// KeyByClientIP keys the requests by requests.GetClientIP
+func KeyByClientIP(r *http.Request) (string, bool) {
+	ip := requests.GetClientIP(r)
+	return ip, ip != ""
+}
+
+// KeyByClientApp keys the requests by the ID of the client app in the request context (clients.WithClientConf)
+func KeyByClientApp(r *http.Request) (string, bool) {
+	conf, ok := clients.ClientConfFromContext(r.Context())
+	if !ok || conf.ID == "" {
+		return "", false
+	}
+	return conf.ID, true
+}
+
+// KeyByHeader keys the requests by the value of the header. e.g. "X-API-Key"
+// Requests without the header are not limited
+func KeyByHeader(name string) KeyFunc[string] {
+	return func(r *http.Request) (string, bool) {
+		v := r.Header.Get(name)
+		return v, v != ""
+	}
+}
+
+// KeyByWebSessionUser keys the requests by the user ID of the web login session in the request context (session.WithWebSessionId)
+// Looks up the session in the kvdb per request. Requests without a valid session are not limited
+// manager is called per request for hot reload. e.g. Core.GetWebSessionManager
+func KeyByWebSessionUser(manager func() *session.Manager) KeyFunc[string] {
+	return func(r *http.Request) (string, bool) {
+		sessionID, ok := session.WebSessionIdFromContext(r.Context())
+		if !ok {
+			return "", false
+		}
+		m := manager()
+		if m == nil {
+			return "", false
+		}
+		info, err := m.GetWebLoginSessionInfoBySessionId(r.Context(), sessionID)
+		if err != nil {
+			return "", false
+		}
+		return info.UserIDStr, info.UserIDStr != ""
+	}
+}
@@ -0,0 +1,70 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrExceedsBurst is returned when taking or waiting for more tokens than the bucket can hold, or negative ones
+	ErrExceedsBurst = errors.New("throttle: tokens exceed the burst")
+	// ErrGroupNotFound is returned when the BucketGroup is not set in the BucketStore
+	ErrGroupNotFound = errors.New("throttle: bucket group not found")
+)
+
+// Reservation is the tokens consumed in advance by Reserve
+type Reservation struct {
+	OK     bool      // false = nothing consumed
+	At     time.Time // when the tokens can be used. zero if never
+	cancel func()
+	once   sync.Once
+}
+
+// Delay returns the wait from now until the tokens can be used
+func (r *Reservation) Delay(now time.Time) time.Duration {
+	return max(r.At.Sub(now), 0)
+}
+
+// Cancel gives back the tokens if they are not usable yet. e.g. the caller gave up waiting
+func (r *Reservation) Cancel() {
+	if !r.OK || r.cancel == nil || !time.Now().Before(r.At) {
+		return
+	}
+	r.once.Do(r.cancel)
+}
+
+// wait reserves within the deadline of ctx and sleeps until the reservation is usable.
+// The reservation is cancelled if ctx is done meanwhile
+func wait(ctx context.Context, reserve func(maxDelay time.Duration) *Reservation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	maxDelay := time.Duration(math.MaxInt64)
+	if deadline, ok := ctx.Deadline(); ok {
+		maxDelay = max(time.Until(deadline), 0)
+	}
+	r := reserve(maxDelay)
+	if !r.OK {
+		if r.At.IsZero() {
+			return ErrExceedsBurst
+		}
+		return fmt.Errorf("throttle: tokens available in %v after the deadline: %w", time.Until(r.At), context.DeadlineExceeded)
+	}
+	delay := r.Delay(time.Now())
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
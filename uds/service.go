@@ -10,6 +10,7 @@ import (
 	"net"
 	"os"
 	"strings"
+	"sync/atomic"
 
 	"github.com/zeptools/gw-core/svc"
 )
@@ -20,7 +21,7 @@ type Service struct {
 	Ctx           context.Context // Service Context
 
 	cancel   context.CancelFunc // Service Context CancelFunc
-	state    int                // internal service state
+	state    atomic.Int32       // internal service state
 	done     chan error         // Shutdown Error Channel
 	listener net.Listener
 }
@@ -29,22 +30,34 @@ func (s *Service) Name() string {
 	return "UDSService"
 }
 
+// State returns the current service state. svc.StateREADY, svc.StateRUNNING or svc.StateSTOPPED
+func (s *Service) State() int {
+	return int(s.state.Load())
+}
+
 func NewService(parentCtx context.Context, conf Conf, cmdStore *CommandStore) *Service {
 	svcCtx, svcCancel := context.WithCancel(parentCtx)
-	return &Service{
+	s := &Service{
 		Ctx:          svcCtx,
 		cancel:       svcCancel,
-		state:        svc.StateREADY,
 		done:         make(chan error, 1),
 		Conf:         conf,
 		CommandStore: cmdStore,
 	}
+	s.state.Store(svc.StateREADY)
+	return s
 }
 
 // Start the unix socket service in the background.
 // Bootstrapping errors are returned immediately.
 // Runtime errors are pushed into Done().
 func (s *Service) Start() error {
+	if s.state.Load() == svc.StateRUNNING {
+		return fmt.Errorf("already started")
+	}
+	if s.state.Load() != svc.StateREADY {
+		return fmt.Errorf("cannot start. not ready")
+	}
 	// clean up old socket if any
 	_ = os.Remove(s.Conf.SocketPath)
 	// create socket
@@ -59,13 +72,18 @@ func (s *Service) Start() error {
 		_ = os.Remove(s.Conf.SocketPath)
 		return fmt.Errorf("chmod(%q) failed: %w", s.Conf.SocketPath, err)
 	}
+	s.state.Store(svc.StateRUNNING)
 	go s.run()
 	return nil
 }
 
 func (s *Service) Stop() {
+	if s.state.Load() != svc.StateRUNNING {
+		log.Println("[ERROR][UDS] cannot stop. not running")
+		return
+	}
 	s.cancel()
-	s.state = svc.StateSTOPPED
+	s.state.Store(svc.StateSTOPPED)
 	log.Println("[INFO][UDS] service stopped")
 }
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/zeptools/gw-core/svc"
@@ -14,49 +15,60 @@ import (
 type Service struct {
 	Ctx    context.Context    // Service Context
 	cancel context.CancelFunc // Service Context CancelFunc
-	state  int                // internal service state
+	state  atomic.Int32       // internal service state
 	done   chan error         // Shutdown Error Channel
 	Server *http.Server
+
+	drainTimeout time.Duration // bounds the graceful shutdown. svc.Manager sets Options.DrainTimeout. 0 = svc.DefaultDrainTimeout
 }
 
+// Ensure Service implements svc.DrainTimeoutSetter
+var _ svc.DrainTimeoutSetter = (*Service)(nil)
+
 func (s *Service) Name() string {
 	return "WebService"
 }
 
+// State returns the current service state. svc.StateREADY, svc.StateRUNNING or svc.StateSTOPPED
+func (s *Service) State() int {
+	return int(s.state.Load())
+}
+
 func NewService(parentCtx context.Context, addr string, router http.Handler) *Service {
 	svcCtx, svcCancel := context.WithCancel(parentCtx)
-	return &Service{
+	s := &Service{
 		Ctx:    svcCtx,
 		cancel: svcCancel,
-		state:  svc.StateREADY,
 		done:   make(chan error, 1),
 		Server: &http.Server{
 			Addr:    addr,
 			Handler: router,
 		},
 	}
+	s.state.Store(svc.StateREADY)
+	return s
 }
 
 func (s *Service) Start() error {
-	if s.state == svc.StateRUNNING {
+	if s.state.Load() == svc.StateRUNNING {
 		return fmt.Errorf("already started")
 	}
-	if s.state != svc.StateREADY {
+	if s.state.Load() != svc.StateREADY {
 		return fmt.Errorf("cannot start. not ready")
 	}
-	s.state = svc.StateRUNNING
+	s.state.Store(svc.StateRUNNING)
 	log.Println("[INFO][HTTP] service started")
 	go s.run()
 	return nil
 }
 
 func (s *Service) Stop() {
-	if s.state != svc.StateRUNNING {
+	if s.state.Load() != svc.StateRUNNING {
 		log.Println("[ERROR][HTTP] cannot stop. not running")
 		return
 	}
 	s.cancel()
-	s.state = svc.StateSTOPPED
+	s.state.Store(svc.StateSTOPPED)
 	log.Println("[INFO][HTTP] service stopped")
 }
 
@@ -64,28 +76,41 @@ func (s *Service) Done() <-chan error {
 	return s.done
 }
 
+// SetDrainTimeout sets the bound of the graceful shutdown. Must be called before Start
+func (s *Service) SetDrainTimeout(timeout time.Duration) {
+	s.drainTimeout = timeout
+}
+
+// run reports Done exactly once: when the listener fails, or when the graceful shutdown returns
 func (s *Service) run() {
-	// clean up with graceful shutdown
-	go func() {
-		<-s.Ctx.Done()
-		log.Println("[INFO][HTTPServer] stopping...")
-		s.Server.SetKeepAlivesEnabled(false)
-		gracefulShutdownCtx, cancel := context.WithTimeout(s.Ctx, 15*time.Second)
-		defer cancel()
-		if err := s.Server.Shutdown(gracefulShutdownCtx); err != nil {
-			log.Printf("[ERROR][HTTPServer] shutdown failed: %v", err)
-			s.done <- err
-		} else {
-			s.done <- nil
-		}
-	}()
-	// run the server in background, push the server error to the error channel
+	// run the server in background. ErrServerClosed is reported by the shutdown below
+	listenErr := make(chan error, 1)
 	go func() {
 		log.Printf("[INFO][HTTPServer] listening on %s ...", s.Server.Addr)
 		if err := s.Server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			s.done <- err
-		} else {
-			s.done <- nil
+			listenErr <- err
 		}
 	}()
+	select {
+	case err := <-listenErr:
+		log.Printf("[ERROR][HTTPServer] listen failed: %v", err)
+		s.done <- err
+		return
+	case <-s.Ctx.Done():
+	}
+	// clean up with graceful shutdown
+	log.Println("[INFO][HTTPServer] stopping...")
+	s.Server.SetKeepAlivesEnabled(false)
+	timeout := s.drainTimeout
+	if timeout <= 0 {
+		timeout = svc.DefaultDrainTimeout
+	}
+	// s.Ctx is already cancelled. only the timeout bounds the shutdown
+	gracefulShutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(s.Ctx), timeout)
+	defer cancel()
+	err := s.Server.Shutdown(gracefulShutdownCtx)
+	if err != nil {
+		log.Printf("[ERROR][HTTPServer] shutdown failed: %v", err)
+	}
+	s.done <- err
 }
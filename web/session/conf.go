@@ -2,6 +2,7 @@ package session
 
 type Conf struct {
 	EncryptionKey string `json:"enckey"`
+	KVDBName      string `json:"kvdb"` // Name of the Key-value DB Client in .kv-databases.json. empty = default
 	ExpireSliding int    `json:"expire_sliding"`
 	ExpireHardcap int    `json:"expire_hardcap"`
 
@@ -2,4 +2,8 @@ package session
 
 const (
 	CookieName = "__Host-session" // RFC-6265bis `__Host-` prefix
+
+	// CreateSessionTxRetries is the max attempts of the session creation transaction
+	// when the user's session list is modified concurrently by another instance
+	CreateSessionTxRetries = 5
 )
@@ -5,10 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"sync"
 	"time"
 
 	"github.com/zeptools/gw-core/db/kvdb"
+	"github.com/zeptools/gw-core/locks/keymutex"
 	"github.com/zeptools/gw-core/security"
 	"github.com/zeptools/gw-core/web/session/login"
 )
@@ -17,7 +17,7 @@ type Manager struct {
 	Conf              Conf
 	Cipher            *security.XChaCha20Poly1305Cipher
 	AppName           string // for session key, etc.
-	SessionLocks      *sync.Map
+	SessionLocks      *keymutex.Mutex
 	BackendKVDBClient kvdb.Client
 }
 
@@ -75,6 +75,7 @@ func (m *Manager) RemoveWebSessionCookie(w http.ResponseWriter) {
 }
 
 // CreateWebLoginSession creates a Session in Key-value Database and Returns its Session ID
+// The session is created, and the oldest sessions of the user over MaxCntPerUser are removed, in one transaction
 func (m *Manager) CreateWebLoginSession(ctx context.Context, accessToken string, refreshToken string, uidStr string) (string, error) {
 	webSessionID, err := GenerateWebSessionID()
 	if err != nil {
@@ -82,66 +83,76 @@ func (m *Manager) CreateWebLoginSession(ctx context.Context, accessToken string,
 	}
 	// Store session_id in KvDB with access_token and refresh_token
 	key := m.WebSessionIDToKVDBKey(webSessionID)
-	if err = m.BackendKVDBClient.SetFields(ctx, key, map[string]any{
+	sessionFields := map[string]any{
 		"access_token":  accessToken,
 		"refresh_token": refreshToken,
 		"uid":           uidStr,
-	}); err != nil {
-		return "", err
 	}
 
 	slidingExpiration := time.Duration(m.Conf.ExpireSliding) * time.Second
 	hardcapExpiration := time.Duration(m.Conf.ExpireHardcap) * time.Second
 
-	ok, err := m.BackendKVDBClient.Expire(ctx, key, slidingExpiration)
-	if err != nil {
-		return "", err
-	}
-	if !ok {
-		return "", errors.New("failed to set session expiration")
-	}
-
-	if m.Conf.MaxCntPerUser > 0 {
-		usrSessionListKey := fmt.Sprintf("%s_wsessions:%s", m.AppName, uidStr)
-		// SessionList Lock (User Level Lock)
-		mu, _ := m.SessionLocks.LoadOrStore(usrSessionListKey, &sync.Mutex{})
-		mutex := mu.(*sync.Mutex)
-
-		mutex.Lock() // waits until this gets the lock if it's locked by another goroutine
-		defer mutex.Unlock()
-		// No need to delete the lock. Keep it for reusing it's tiny in memory.
-		// By keeping it, no overhead to create(LoadOrStore)/delete the lock
-		// If you wants to delete the lock every time:
-		//defer func() {
-		//	mutex.Unlock()
-		//	env.SessionLocks.Delete(usrSessionListKey)
-		//}()
-
-		if err = m.BackendKVDBClient.Push(ctx, usrSessionListKey, webSessionID); err != nil {
+	if m.Conf.MaxCntPerUser <= 0 {
+		b := m.BackendKVDBClient.TxPipeline()
+		b.SetFields(key, sessionFields)
+		expired := b.Expire(key, slidingExpiration)
+		if err = b.Exec(ctx); err != nil {
 			return "", err
 		}
-		sessionCnt, err := m.BackendKVDBClient.Len(ctx, usrSessionListKey)
-		if err != nil {
-			return "", err
+		if !expired.Val() {
+			return "", errors.New("failed to set session expiration")
 		}
-		if sessionCnt > m.Conf.MaxCntPerUser {
-			diff := sessionCnt - m.Conf.MaxCntPerUser
-			sessionsToDel, err := m.BackendKVDBClient.Range(ctx, usrSessionListKey, 0, diff-1) // []string
+		return webSessionID, nil
+	}
+
+	usrSessionListKey := fmt.Sprintf("%s_wsessions:%s", m.AppName, uidStr)
+	// SessionList Lock (User Level Lock)
+	// waits until this gets the lock if it's locked by another goroutine. removed when the last waiter unlocks
+	if err = m.SessionLocks.Lock(ctx, usrSessionListKey); err != nil {
+		return "", err
+	}
+	defer m.SessionLocks.Unlock(usrSessionListKey)
+
+	// The lock serializes this process only. The session list is watched against other instances
+	for range CreateSessionTxRetries {
+		err = m.BackendKVDBClient.Watch(ctx, func(tx kvdb.Tx) error {
+			sessionIDs, err := m.BackendKVDBClient.Range(ctx, usrSessionListKey, 0, -1)
 			if err != nil {
-				return "", err
+				return err
 			}
 			var keysToDel []string
-			for _, v := range sessionsToDel {
-				keysToDel = append(keysToDel, m.WebSessionIDToKVDBKey(v))
+			if over := int64(len(sessionIDs)) + 1 - m.Conf.MaxCntPerUser; over > 0 {
+				for _, v := range sessionIDs[:over] {
+					keysToDel = append(keysToDel, m.WebSessionIDToKVDBKey(v))
+				}
+			}
+			b := tx.TxPipeline()
+			b.SetFields(key, sessionFields)
+			expired := b.Expire(key, slidingExpiration)
+			b.Push(usrSessionListKey, webSessionID)
+			if len(keysToDel) > 0 {
+				b.Delete(keysToDel...)
+			}
+			b.Trim(usrSessionListKey, -m.Conf.MaxCntPerUser, -1)
+			// the list outlives its newest session. it slides on every login. no hardcap = no expiration
+			if hardcapExpiration > 0 {
+				b.Expire(usrSessionListKey, hardcapExpiration)
+			}
+			if err = b.Exec(ctx); err != nil {
+				return err
 			}
-			_, _ = m.BackendKVDBClient.Delete(ctx, keysToDel...)
-			if err = m.BackendKVDBClient.Trim(ctx, usrSessionListKey, diff, -1); err != nil {
-				return "", err
+			if !expired.Val() {
+				return errors.New("failed to set session expiration")
 			}
-			_, _ = m.BackendKVDBClient.Expire(ctx, usrSessionListKey, hardcapExpiration)
+			return nil
+		}, usrSessionListKey)
+		if !errors.Is(err, kvdb.ErrTxFailed) {
+			break
 		}
 	}
-
+	if err != nil {
+		return "", err
+	}
 	return webSessionID, nil
 }
 